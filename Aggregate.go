@@ -0,0 +1,31 @@
+package sql
+
+// Count returns a COUNT(column) select column, use "*" to count the rows
+func Count(column string, alias string) SelectColumn {
+	return SelectColumn{Column: column, Alias: alias, Aggregate: AGGREGATE_COUNT}
+}
+
+// CountDistinct returns a COUNT(DISTINCT column) select column
+func CountDistinct(column string, alias string) SelectColumn {
+	return SelectColumn{Column: column, Alias: alias, Aggregate: AGGREGATE_COUNT_DISTINCT}
+}
+
+// Sum returns a SUM(column) select column
+func Sum(column string, alias string) SelectColumn {
+	return SelectColumn{Column: column, Alias: alias, Aggregate: AGGREGATE_SUM}
+}
+
+// Avg returns an AVG(column) select column
+func Avg(column string, alias string) SelectColumn {
+	return SelectColumn{Column: column, Alias: alias, Aggregate: AGGREGATE_AVG}
+}
+
+// Min returns a MIN(column) select column
+func Min(column string, alias string) SelectColumn {
+	return SelectColumn{Column: column, Alias: alias, Aggregate: AGGREGATE_MIN}
+}
+
+// Max returns a MAX(column) select column
+func Max(column string, alias string) SelectColumn {
+	return SelectColumn{Column: column, Alias: alias, Aggregate: AGGREGATE_MAX}
+}
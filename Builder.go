@@ -1,20 +1,38 @@
 package sql
 
 import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gouniverse/utils"
 	"github.com/samber/lo"
 )
 
 type Where struct {
-	Raw      string
-	Column   string
-	Operator string
-	Type     string
-	Value    string
+	Raw    string
+	Column string
+	// Aggregate wraps the column in an aggregate function (AGGREGATE_*),
+	// as used by HAVING conditions
+	Aggregate string
+	Operator  string
+	Type      string
+	Value     string
+	// TypedValue is bound instead of Value when not nil, keeping its Go
+	// type, i.e. to compare numbers, times or driver.Valuer values
+	TypedValue any
+	// Values holds the list for IN / NOT IN and the range for BETWEEN
+	Values []string
+	// Subquery is compared against, or checked for EXISTS
+	Subquery *Builder
+	// Group nests the conditions in parentheses, of any depth
+	Group []Where
 }
 
 type GroupBy struct {
@@ -26,20 +44,93 @@ type OrderBy struct {
 	Direction string
 }
 
+type SelectColumn struct {
+	// Table qualifies the column, i.e. Table "u" with Column "*" is u.*
+	Table  string
+	Column string
+	Alias  string
+	// Raw is an expression rendered as is, i.e. COALESCE(name, '')
+	Raw string
+	// Aggregate wraps the column in an aggregate function (AGGREGATE_*)
+	Aggregate string
+	Subquery  *Builder
+}
+
+type OnConflict struct {
+	// Columns are the unique columns causing the conflict
+	Columns []string
+	// UpdateColumns are updated with the new values, by default all
+	// the inserted columns except the conflict ones
+	UpdateColumns []string
+	// DoNothing keeps the existing row as it is
+	DoNothing bool
+}
+
+type Join struct {
+	Type  string
+	Table string
+	Alias string
+	On    []JoinOn
+	Using []string
+}
+
+type JoinOn struct {
+	Column      string
+	Operator    string
+	OtherColumn string
+}
+
 type Builder struct {
 	Dialect string
 	//TableName    string
-	sql            map[string]any
-	sqlColumns     []map[string]any
-	sqlGroupBy     []GroupBy
-	sqlLimit       int64
-	sqlOffset      int64
-	sqlOrderBy     []OrderBy
-	sqlTableName   string
-	sqlViewName    string
-	sqlViewColumns []string
-	sqlViewSQL     string
-	sqlWhere       []Where
+	sql              map[string]any
+	sqlColumns       []map[string]any
+	sqlDistinct      bool
+	sqlGroupBy       []GroupBy
+	sqlHaving        []Where
+	sqlJoins         []Join
+	sqlLimit         int64
+	sqlOffset        int64
+	sqlOrderBy       []OrderBy
+	sqlParams        []any
+	sqlParamsBatches [][]any
+	sqlParamsLimit   int
+	sqlReturning     []string
+	sqlParamsOn      bool
+	sqlSelectColumns []SelectColumn
+	sqlTableName     string
+	sqlTableSubquery *Builder
+	sqlViewName      string
+	sqlViewColumns   []string
+	sqlViewSQL       string
+	sqlWhere         []Where
+}
+
+// WithParams switches the builder to parameterized output. Values are no
+// longer inlined, but rendered as dialect placeholders (? for MySQL and
+// SQLite, $1..$n for Postgres) and collected in order, see Params()
+func (b *Builder) WithParams() *Builder {
+	b.sqlParamsOn = true
+	return b
+}
+
+// Params returns the ordered arguments for the placeholders of the last
+// generated statement, ready to be passed to Database.Exec or Database.Query
+func (b *Builder) Params() []any {
+	return b.sqlParams
+}
+
+// BatchParams returns the arguments for each of the statements returned
+// by the last InsertMany, in the same order
+func (b *Builder) BatchParams() [][]any {
+	return b.sqlParamsBatches
+}
+
+// ParamsLimit overrides the maximum number of placeholders per statement
+// of the dialect, i.e. 32766 for SQLite 3.32 and newer
+func (b *Builder) ParamsLimit(limit int) *Builder {
+	b.sqlParamsLimit = limit
+	return b
 }
 
 func (b *Builder) Table(tableName string) *Builder {
@@ -95,13 +186,13 @@ func (b *Builder) Create() string {
 	sql := ""
 
 	if isTable {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			sql = `CREATE TABLE ` + b.quoteTable(b.sqlTableName) + `(` + b.columnsToSQL(b.sqlColumns) + `);`
 		}
 	}
 
 	if isView {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			viewColumnsToSQL := strings.Join(lo.Map(b.sqlViewColumns, func(columnName string, _ int) string {
 				return b.quoteColumn(columnName)
 			}), ", ")
@@ -114,6 +205,24 @@ func (b *Builder) Create() string {
 	return sql
 }
 
+// CreateTableAs creates the table with the columns and rows of the select,
+// SQL Server uses SELECT ... INTO instead
+func (b *Builder) CreateTableAs(selectBuilder *Builder) string {
+	if b.sqlTableName == "" {
+		panic("In method CreateTableAs() no table specified to create!")
+	}
+
+	b.sqlParams = []any{}
+
+	selectSql := b.subqueryToSql(selectBuilder)
+
+	if b.Dialect == DIALECT_MSSQL {
+		return "SELECT * INTO " + b.quoteTable(b.sqlTableName) + " FROM (" + selectSql + ") AS " + b.quoteTable("source") + ";"
+	}
+
+	return "CREATE TABLE " + b.quoteTable(b.sqlTableName) + " AS " + selectSql + ";"
+}
+
 func (b *Builder) CreateIfNotExists() string {
 	isView := b.sqlViewName != ""
 	isTable := b.sqlTableName != ""
@@ -130,10 +239,13 @@ func (b *Builder) CreateIfNotExists() string {
 		if b.Dialect == DIALECT_SQLITE {
 			sql = "CREATE TABLE IF NOT EXISTS " + b.quoteTable(b.sqlTableName) + "(" + b.columnsToSQL(b.sqlColumns) + ");"
 		}
+		if b.Dialect == DIALECT_MSSQL {
+			sql = "IF OBJECT_ID(" + b.quoteValue(b.sqlTableName) + ", N'U') IS NULL CREATE TABLE " + b.quoteTable(b.sqlTableName) + "(" + b.columnsToSQL(b.sqlColumns) + ");"
+		}
 	}
 
 	if isView {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			viewColumnsToSQL := strings.Join(lo.Map(b.sqlViewColumns, func(columnName string, _ int) string {
 				return b.quoteColumn(columnName)
 			}), ", ")
@@ -143,6 +255,9 @@ func (b *Builder) CreateIfNotExists() string {
 			if b.Dialect == DIALECT_MYSQL {
 				sqlStart = "CREATE OR REPLACE VIEW"
 			}
+			if b.Dialect == DIALECT_MSSQL {
+				sqlStart = "CREATE OR ALTER VIEW"
+			}
 
 			sql = sqlStart + ` ` + b.quoteTable(b.sqlViewName) + viewColumns + " AS " + b.sqlViewSQL
 		}
@@ -167,6 +282,8 @@ func (b *Builder) Delete() string {
 		panic("In method Delete() no table specified to delete from!")
 	}
 
+	b.sqlParams = []any{}
+
 	where := ""
 	if len(b.sqlWhere) > 0 {
 		where = b.whereToSql(b.sqlWhere)
@@ -177,19 +294,11 @@ func (b *Builder) Delete() string {
 		orderBy = b.orderByToSql(b.sqlOrderBy)
 	}
 
-	limit := ""
-	if b.sqlLimit > 0 {
-		limit = " LIMIT " + strconv.FormatInt(b.sqlLimit, 10)
-	}
-
-	offset := ""
-	if b.sqlOffset > 0 {
-		offset = " OFFSET " + strconv.FormatInt(b.sqlOffset, 10)
-	}
+	top, limit := b.modifyLimitToSql("Delete")
 
 	sql := ""
-	if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
-		sql = "DELETE FROM " + b.quoteTable(b.sqlTableName) + where + orderBy + limit + offset + ";"
+	if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
+		sql = "DELETE" + top + " FROM " + b.quoteTable(b.sqlTableName) + b.outputToSql("DELETED") + where + orderBy + limit + b.returningToSql() + ";"
 	}
 	return sql
 }
@@ -202,13 +311,13 @@ func (b *Builder) Drop() string {
 	sql := ""
 
 	if isTable {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			sql = "DROP TABLE " + b.quoteTable(b.sqlTableName) + ";"
 		}
 	}
 
 	if isView {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			sql = "DROP VIEW " + b.quoteTable(b.sqlViewName) + ";"
 		}
 	}
@@ -223,13 +332,13 @@ func (b *Builder) DropIfExists() string {
 	sql := ""
 
 	if isTable {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			sql = "DROP TABLE IF EXISTS " + b.quoteTable(b.sqlTableName) + ";"
 		}
 	}
 
 	if isView {
-		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
 			sql = "DROP VIEW IF EXISTS " + b.quoteTable(b.sqlViewName) + ";"
 		}
 	}
@@ -237,6 +346,15 @@ func (b *Builder) DropIfExists() string {
 	return sql
 }
 
+// Returning makes Insert, Update, Delete and Upsert return the columns of
+// the affected rows, with RETURNING on Postgres and SQLite (3.35+) and
+// OUTPUT on SQL Server. MySQL has no RETURNING and ignores it, use
+// Database.InsertReturning to read back an inserted row there
+func (b *Builder) Returning(columns ...string) *Builder {
+	b.sqlReturning = columns
+	return b
+}
+
 func (b *Builder) Limit(limit int64) *Builder {
 	b.sqlLimit = limit
 	return b
@@ -252,6 +370,20 @@ func (b *Builder) GroupBy(groupBy GroupBy) *Builder {
 	return b
 }
 
+// Having adds a HAVING condition, filtering the groups of GroupBy. The
+// aggregates are numbers, compare them with a TypedValue, a string Value is
+// compared as text
+func (b *Builder) Having(having Where) *Builder {
+	b.sqlHaving = append(b.sqlHaving, having)
+	return b
+}
+
+// OrHaving adds a HAVING condition joined with OR to the previous ones
+func (b *Builder) OrHaving(having Where) *Builder {
+	having.Type = "OR"
+	return b.Having(having)
+}
+
 func (b *Builder) OrderBy(columnName string, direction string) *Builder {
 	if strings.EqualFold(direction, "desc") || strings.EqualFold(direction, "descending") {
 		direction = "DESC"
@@ -267,6 +399,35 @@ func (b *Builder) OrderBy(columnName string, direction string) *Builder {
 	return b
 }
 
+// Join adds a plain JOIN, the join type is taken from join.Type
+func (b *Builder) Join(join Join) *Builder {
+	b.sqlJoins = append(b.sqlJoins, join)
+	return b
+}
+
+func (b *Builder) InnerJoin(join Join) *Builder {
+	join.Type = JOIN_INNER
+	return b.Join(join)
+}
+
+func (b *Builder) LeftJoin(join Join) *Builder {
+	join.Type = JOIN_LEFT
+	return b.Join(join)
+}
+
+func (b *Builder) RightJoin(join Join) *Builder {
+	join.Type = JOIN_RIGHT
+	return b.Join(join)
+}
+
+// CrossJoin adds a CROSS JOIN, any ON or USING conditions are ignored
+func (b *Builder) CrossJoin(join Join) *Builder {
+	join.Type = JOIN_CROSS
+	join.On = nil
+	join.Using = nil
+	return b.Join(join)
+}
+
 /** The <b>select</b> method selects rows from a table, based on criteria.
  * <code>
  * // Selects all the rows from the table
@@ -286,11 +447,71 @@ func (b *Builder) Select(columns []string) string {
 		panic("In method Delete() no table specified to delete from!")
 	}
 
-	join := "" // TODO
+	b.sqlParams = []any{}
 
-	groupBy := ""
-	if len(b.sqlGroupBy) > 0 {
-		groupBy = b.groupByToSql(b.sqlGroupBy)
+	sql := b.selectToSql(columns)
+
+	if sql == "" {
+		return sql
+	}
+
+	return sql + ";"
+}
+
+// Distinct makes the select return only distinct rows
+func (b *Builder) Distinct() *Builder {
+	b.sqlDistinct = true
+	return b
+}
+
+// SelectColumns adds typed columns to the select list, after the
+// ones given to Select. A builder used as a subquery selects these
+func (b *Builder) SelectColumns(columns ...SelectColumn) *Builder {
+	b.sqlSelectColumns = append(b.sqlSelectColumns, columns...)
+	return b
+}
+
+// FromSubquery selects from the subquery instead of a table, the alias
+// names the derived table
+func (b *Builder) FromSubquery(subquery *Builder, alias string) *Builder {
+	b.sqlTableName = alias
+	b.sqlTableSubquery = subquery
+	return b
+}
+
+// selectToSql converts the select to SQL without the closing semicolon,
+// the pieces are rendered in textual order to keep the placeholders in order
+func (b *Builder) selectToSql(columns []string) string {
+	columnsStr := "*"
+
+	// The columns are copied, the slice of the caller is not modified
+	columnsSql := []string{}
+
+	for _, column := range columns {
+		columnsSql = append(columnsSql, b.selectColumnToSql(b.selectColumnFromString(column)))
+	}
+
+	for _, column := range b.sqlSelectColumns {
+		columnsSql = append(columnsSql, b.selectColumnToSql(column))
+	}
+
+	if len(columnsSql) > 0 {
+		columnsStr = strings.Join(columnsSql, ", ")
+	}
+
+	distinct := ""
+	if b.sqlDistinct {
+		distinct = "DISTINCT "
+	}
+
+	from := b.quoteTable(b.sqlTableName)
+	if b.sqlTableSubquery != nil {
+		from = "(" + b.subqueryToSql(b.sqlTableSubquery) + ") AS " + b.quoteTable(b.sqlTableName)
+	}
+
+	join := ""
+	if len(b.sqlJoins) > 0 {
+		join = b.joinToSql(b.sqlJoins)
 	}
 
 	where := ""
@@ -298,36 +519,93 @@ func (b *Builder) Select(columns []string) string {
 		where = b.whereToSql(b.sqlWhere)
 	}
 
+	groupBy := ""
+	if len(b.sqlGroupBy) > 0 {
+		groupBy = b.groupByToSql(b.sqlGroupBy)
+	}
+
+	having := ""
+	if len(b.sqlHaving) > 0 {
+		having = b.havingToSql(b.sqlHaving)
+	}
+
 	orderBy := ""
 	if len(b.sqlOrderBy) > 0 {
 		orderBy = b.orderByToSql(b.sqlOrderBy)
 	}
 
-	limit := ""
-	if b.sqlLimit > 0 {
-		limit = " LIMIT " + strconv.FormatInt(b.sqlLimit, 10)
-	}
+	top, limit := b.selectLimitToSql()
 
-	offset := ""
-	if b.sqlOffset > 0 {
-		offset = " OFFSET " + strconv.FormatInt(b.sqlOffset, 10)
+	// SQL Server only pages with OFFSET ... FETCH after an ORDER BY
+	if b.Dialect == DIALECT_MSSQL && limit != "" && orderBy == "" {
+		orderBy = " ORDER BY (SELECT NULL)"
 	}
 
-	columnsStr := "*"
+	sql := ""
 
-	if len(columns) > 0 {
-		for index, column := range columns {
-			columns[index] = b.quoteColumn(column)
-		}
-		columnsStr = strings.Join(columns, ", ")
+	if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
+		sql = "SELECT " + distinct + top + columnsStr + " FROM " + from + join + where + groupBy + having + orderBy + limit
 	}
 
+	return sql
+}
+
+// selectColumnToSql converts a typed select column to SQL
+func (b *Builder) selectColumnToSql(column SelectColumn) string {
 	sql := ""
 
-	if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES || b.Dialect == DIALECT_SQLITE {
-		sql = "SELECT " + columnsStr + " FROM " + b.quoteTable(b.sqlTableName) + join + where + groupBy + orderBy + limit + offset + ";"
+	columnName := column.Column
+	if column.Table != "" {
+		columnName = column.Table + "." + columnName
+	}
+
+	if column.Raw != "" {
+		sql = column.Raw
+	} else if column.Subquery != nil {
+		sql = "(" + b.subqueryToSql(column.Subquery) + ")"
+	} else if column.Aggregate != "" {
+		sql = b.aggregateToSql(column.Aggregate, columnName)
+	} else {
+		sql = b.quoteColumn(columnName)
+	}
+
+	if column.Alias != "" {
+		sql += " AS " + b.quoteColumn(column.Alias)
+	}
+
+	return sql
+}
+
+// selectColumnFromString converts a column given to Select to a typed
+// column, splitting off an alias given as "column AS alias"
+func (b *Builder) selectColumnFromString(column string) SelectColumn {
+	asIndex := strings.LastIndex(strings.ToUpper(column), " AS ")
+
+	if asIndex < 0 {
+		return SelectColumn{Column: column}
 	}
 
+	return SelectColumn{
+		Column: strings.TrimSpace(column[:asIndex]),
+		Alias:  strings.TrimSpace(column[asIndex+4:]),
+	}
+}
+
+// subqueryToSql renders the subquery sharing the parameters of this
+// builder, so the placeholders are numbered across both statements
+func (b *Builder) subqueryToSql(subquery *Builder) string {
+	if subquery.Dialect != b.Dialect {
+		panic("Subquery dialect " + subquery.Dialect + " does not match the query dialect " + b.Dialect + "!")
+	}
+
+	paramsOn, params := subquery.sqlParamsOn, subquery.sqlParams
+	subquery.sqlParamsOn, subquery.sqlParams = b.sqlParamsOn, b.sqlParams
+
+	sql := subquery.selectToSql([]string{})
+
+	b.sqlParams = subquery.sqlParams
+	subquery.sqlParamsOn, subquery.sqlParams = paramsOn, params
+
 	return sql
 }
 
@@ -341,21 +619,215 @@ func (b *Builder) Select(columns []string) string {
  * @return int 0 or 1, on success, false, otherwise
  * @access public
  */
-func (b *Builder) Insert(columnValuesMap map[string]string) string {
+func (b *Builder) Insert(columnValuesMap map[string]any) string {
 	if b.sqlTableName == "" {
 		panic("In method Insert() no table specified to insert in!")
 	}
 
-	limit := ""
-	if b.sqlLimit > 0 {
-		limit = " LIMIT " + strconv.FormatInt(b.sqlLimit, 10)
+	b.sqlParams = []any{}
+
+	// LIMIT and OFFSET are not valid for INSERT, and are not rendered
+
+	_, columnNames, columnValues := b.insertValuesToSql(columnValuesMap)
+
+	return "INSERT INTO " + b.quoteTable(b.sqlTableName) + " (" + strings.Join(columnNames, ", ") + ")" + b.outputToSql("INSERTED") + " VALUES (" + strings.Join(columnValues, ", ") + ")" + b.returningToSql() + ";"
+}
+
+// InsertFromSelect returns an INSERT of the rows of the select, copying them
+// server side. The columns are matched with the select columns by position,
+// when empty all the table columns are filled
+func (b *Builder) InsertFromSelect(columns []string, selectBuilder *Builder) string {
+	if b.sqlTableName == "" {
+		panic("In method InsertFromSelect() no table specified to insert in!")
 	}
 
-	offset := ""
-	if b.sqlOffset > 0 {
-		offset = " OFFSET " + strconv.FormatInt(b.sqlOffset, 10)
+	b.sqlParams = []any{}
+
+	columnsSql := ""
+	if len(columns) > 0 {
+		columnNames := lo.Map(columns, func(columnName string, _ int) string {
+			return b.quoteColumn(columnName)
+		})
+		columnsSql = " (" + strings.Join(columnNames, ", ") + ")"
+	}
+
+	return "INSERT INTO " + b.quoteTable(b.sqlTableName) + columnsSql + b.outputToSql("INSERTED") + " " + b.subqueryToSql(selectBuilder) + b.returningToSql() + ";"
+}
+
+// InsertMany returns multi-row INSERT statements for the rows, which must all
+// have the same columns. Usually there is a single statement, the rows are
+// split in more when they exceed the placeholder limit of the dialect (with
+// parameters enabled, see BatchParams), or the 1000 rows per VALUES limit of
+// SQL Server
+func (b *Builder) InsertMany(rows []map[string]any) []string {
+	if b.sqlTableName == "" {
+		panic("In method InsertMany() no table specified to insert in!")
 	}
 
+	b.sqlParams = []any{}
+	b.sqlParamsBatches = [][]any{}
+
+	if len(rows) < 1 {
+		return []string{}
+	}
+
+	keys := lo.Keys(rows[0])
+	sort.Strings(keys)
+
+	for index, row := range rows {
+		rowKeys := lo.Keys(row)
+		if len(rowKeys) != len(keys) || len(lo.Without(rowKeys, keys...)) > 0 {
+			panic("In method InsertMany() row " + strconv.Itoa(index) + " has different columns than the first row!")
+		}
+	}
+
+	columnNames := lo.Map(keys, func(columnName string, _ int) string {
+		return b.quoteColumn(columnName)
+	})
+
+	sqlStart := "INSERT INTO " + b.quoteTable(b.sqlTableName) + " (" + strings.Join(columnNames, ", ") + ") VALUES "
+
+	sqls := []string{}
+
+	for _, batch := range lo.Chunk(rows, b.insertManyBatchSize(len(keys))) {
+		b.sqlParams = []any{}
+
+		rowsSql := lo.Map(batch, func(row map[string]any, _ int) string {
+			values := lo.Map(keys, func(columnName string, _ int) string {
+				return b.bindValue(row[columnName])
+			})
+			return "(" + strings.Join(values, ", ") + ")"
+		})
+
+		sqls = append(sqls, sqlStart+strings.Join(rowsSql, ", ")+";")
+		b.sqlParamsBatches = append(b.sqlParamsBatches, b.sqlParams)
+	}
+
+	return sqls
+}
+
+// insertManyBatchSize returns the number of rows per statement allowed by
+// the placeholder limit of the dialect, or by the SQL Server VALUES limit
+func (b *Builder) insertManyBatchSize(columnCount int) int {
+	batchSize := math.MaxInt
+
+	if b.Dialect == DIALECT_MSSQL {
+		batchSize = 1000
+	}
+
+	if !b.sqlParamsOn || columnCount < 1 {
+		return batchSize
+	}
+
+	paramsLimit := b.sqlParamsLimit
+	if paramsLimit < 1 {
+		paramsLimit = lo.
+			If(b.Dialect == DIALECT_SQLITE, 999).
+			ElseIf(b.Dialect == DIALECT_MSSQL, 2100).
+			Else(65535)
+	}
+
+	return lo.Max([]int{1, lo.Min([]int{batchSize, paramsLimit / columnCount})})
+}
+
+// Upsert returns an INSERT which updates the existing row on a conflict:
+// ON DUPLICATE KEY UPDATE for MySQL, ON CONFLICT for Postgres and SQLite,
+// and MERGE for SQL Server. MySQL uses the table keys, and a no-op update
+// of the first column for OnConflict.DoNothing
+func (b *Builder) Upsert(columnValuesMap map[string]any, onConflict OnConflict) string {
+	if b.sqlTableName == "" {
+		panic("In method Upsert() no table specified to insert in!")
+	}
+
+	if len(columnValuesMap) < 1 {
+		panic("In method Upsert() no columns specified to insert!")
+	}
+
+	// MySQL uses the table keys, Postgres and SQLite can skip any conflict
+	conflictRequired := b.Dialect == DIALECT_MSSQL || (b.Dialect != DIALECT_MYSQL && !onConflict.DoNothing)
+	if conflictRequired && len(onConflict.Columns) < 1 {
+		panic("In method Upsert() no conflict columns specified!")
+	}
+
+	b.sqlParams = []any{}
+
+	keys, columnNames, columnValues := b.insertValuesToSql(columnValuesMap)
+
+	updateColumns := onConflict.UpdateColumns
+	if len(updateColumns) < 1 {
+		updateColumns = lo.Filter(keys, func(columnName string, _ int) bool {
+			return !lo.Contains(onConflict.Columns, columnName)
+		})
+	}
+
+	doNothing := onConflict.DoNothing || len(updateColumns) < 1
+
+	table := b.quoteTable(b.sqlTableName)
+	columnsStr := strings.Join(columnNames, ", ")
+	valuesStr := strings.Join(columnValues, ", ")
+
+	if b.Dialect == DIALECT_MYSQL {
+		// INSERT IGNORE would also turn NOT NULL, truncation and foreign key
+		// errors into warnings, a no-op update skips the conflicts only
+		if doNothing {
+			noopColumn := b.quoteColumn(keys[0])
+			return "INSERT INTO " + table + " (" + columnsStr + ") VALUES (" + valuesStr + ") ON DUPLICATE KEY UPDATE " + noopColumn + "=" + noopColumn + ";"
+		}
+
+		updateSql := lo.Map(updateColumns, func(columnName string, _ int) string {
+			return b.quoteColumn(columnName) + "=VALUES(" + b.quoteColumn(columnName) + ")"
+		})
+
+		return "INSERT INTO " + table + " (" + columnsStr + ") VALUES (" + valuesStr + ") ON DUPLICATE KEY UPDATE " + strings.Join(updateSql, ", ") + ";"
+	}
+
+	if b.Dialect == DIALECT_MSSQL {
+		target := b.quoteTable("target")
+		source := b.quoteTable("source")
+
+		matchSql := lo.Map(onConflict.Columns, func(columnName string, _ int) string {
+			return target + "." + b.quoteColumn(columnName) + " = " + source + "." + b.quoteColumn(columnName)
+		})
+		sourceColumns := lo.Map(columnNames, func(columnName string, _ int) string {
+			return source + "." + columnName
+		})
+
+		sql := "MERGE INTO " + table + " AS " + target + " USING (VALUES (" + valuesStr + ")) AS " + source + " (" + columnsStr + ")"
+		sql += " ON " + strings.Join(matchSql, " AND ")
+
+		if !doNothing {
+			updateSql := lo.Map(updateColumns, func(columnName string, _ int) string {
+				return b.quoteColumn(columnName) + " = " + source + "." + b.quoteColumn(columnName)
+			})
+			sql += " WHEN MATCHED THEN UPDATE SET " + strings.Join(updateSql, ", ")
+		}
+
+		return sql + " WHEN NOT MATCHED THEN INSERT (" + columnsStr + ") VALUES (" + strings.Join(sourceColumns, ", ") + ")" + b.outputToSql("INSERTED") + ";"
+	}
+
+	conflictColumns := lo.Map(onConflict.Columns, func(columnName string, _ int) string {
+		return b.quoteColumn(columnName)
+	})
+
+	conflictTarget := ""
+	if len(conflictColumns) > 0 {
+		conflictTarget = " (" + strings.Join(conflictColumns, ", ") + ")"
+	}
+
+	action := "DO NOTHING"
+	if !doNothing {
+		updateSql := lo.Map(updateColumns, func(columnName string, _ int) string {
+			return b.quoteColumn(columnName) + "=excluded." + b.quoteColumn(columnName)
+		})
+		action = "DO UPDATE SET " + strings.Join(updateSql, ", ")
+	}
+
+	return "INSERT INTO " + table + " (" + columnsStr + ") VALUES (" + valuesStr + ") ON CONFLICT" + conflictTarget + " " + action + b.returningToSql() + ";"
+}
+
+// insertValuesToSql converts the column values of an insert to SQL,
+// returning the column names ordered by name, quoted, and the values
+func (b *Builder) insertValuesToSql(columnValuesMap map[string]any) ([]string, []string, []string) {
 	columnNames := []string{}
 	columnValues := []string{}
 
@@ -369,10 +841,10 @@ func (b *Builder) Insert(columnValuesMap map[string]string) string {
 	for _, columnName := range keys {
 		columnValue := columnValuesMap[columnName]
 		columnNames = append(columnNames, b.quoteColumn(columnName))
-		columnValues = append(columnValues, b.quoteValue(columnValue))
+		columnValues = append(columnValues, b.bindValue(columnValue))
 	}
 
-	return "INSERT INTO " + b.quoteTable(b.sqlTableName) + " (" + strings.Join(columnNames, ", ") + ") VALUES (" + strings.Join(columnValues, ", ") + ")" + limit + offset + ";"
+	return keys, columnNames, columnValues
 }
 
 /**
@@ -385,16 +857,25 @@ func (b *Builder) Insert(columnValuesMap map[string]string) string {
  * @return int 0 or 1, on success, false, otherwise
  * @access public
  */
-func (b *Builder) Update(columnValues map[string]string) string {
+func (b *Builder) Update(columnValues map[string]any) string {
 	if b.sqlTableName == "" {
 		panic("In method Delete() no table specified to delete from!")
 	}
 
-	join := "" // TODO
+	b.sqlParams = []any{}
 
-	groupBy := ""
-	if len(b.sqlGroupBy) > 0 {
-		groupBy = b.groupByToSql(b.sqlGroupBy)
+	// Order keys
+	keys := make([]string, 0, len(columnValues))
+	for k := range columnValues {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// SET is rendered first, so the placeholders follow the textual order
+	updateSql := []string{}
+	for _, columnName := range keys {
+		columnValue := columnValues[columnName]
+		updateSql = append(updateSql, b.quoteColumn(columnName)+"="+b.bindValue(columnValue))
 	}
 
 	where := ""
@@ -402,35 +883,34 @@ func (b *Builder) Update(columnValues map[string]string) string {
 		where = b.whereToSql(b.sqlWhere)
 	}
 
-	orderBy := ""
-	if len(b.sqlOrderBy) > 0 {
-		orderBy = b.orderByToSql(b.sqlOrderBy)
-	}
-
-	limit := ""
-	if b.sqlLimit > 0 {
-		limit = " LIMIT " + strconv.FormatInt(b.sqlLimit, 10)
+	// MySQL joins the tables before SET, SQL Server joins them in FROM,
+	// Postgres and SQLite use UPDATE ... FROM with the join conditions
+	// moved to WHERE
+	joinBeforeSet := ""
+	joinFrom := ""
+	if len(b.sqlJoins) > 0 {
+		if b.Dialect == DIALECT_MYSQL {
+			joinBeforeSet = b.joinToSql(b.sqlJoins)
+		} else if b.Dialect == DIALECT_MSSQL {
+			joinFrom = " FROM " + b.quoteTable(b.sqlTableName) + b.joinToSql(b.sqlJoins)
+		} else {
+			joinFrom, where = b.joinToUpdateFromSql(b.sqlJoins, where)
+		}
 	}
 
-	offset := ""
-	if b.sqlOffset > 0 {
-		offset = " OFFSET " + strconv.FormatInt(b.sqlOffset, 10)
+	groupBy := ""
+	if len(b.sqlGroupBy) > 0 {
+		groupBy = b.groupByToSql(b.sqlGroupBy)
 	}
 
-	// Order keys
-	keys := make([]string, 0, len(columnValues))
-	for k := range columnValues {
-		keys = append(keys, k)
+	orderBy := ""
+	if len(b.sqlOrderBy) > 0 {
+		orderBy = b.orderByToSql(b.sqlOrderBy)
 	}
-	sort.Strings(keys)
 
-	updateSql := []string{}
-	for _, columnName := range keys {
-		columnValue := columnValues[columnName]
-		updateSql = append(updateSql, b.quoteColumn(columnName)+"="+b.quoteValue(columnValue))
-	}
+	top, limit := b.modifyLimitToSql("Update")
 
-	return "UPDATE " + b.quoteTable(b.sqlTableName) + " SET " + strings.Join(updateSql, ", ") + join + where + groupBy + orderBy + limit + offset + ";"
+	return "UPDATE" + top + " " + b.quoteTable(b.sqlTableName) + joinBeforeSet + " SET " + strings.Join(updateSql, ", ") + b.outputToSql("INSERTED") + joinFrom + where + groupBy + orderBy + limit + b.returningToSql() + ";"
 }
 
 func (b *Builder) Where(where Where) *Builder {
@@ -438,6 +918,23 @@ func (b *Builder) Where(where Where) *Builder {
 	return b
 }
 
+// OrWhere adds a condition joined with OR to the previous ones
+func (b *Builder) OrWhere(where Where) *Builder {
+	where.Type = "OR"
+	return b.Where(where)
+}
+
+// WhereGroup adds the conditions as a parenthesised group joined with AND.
+// The type of each condition decides how it joins the others in the group
+func (b *Builder) WhereGroup(wheres ...Where) *Builder {
+	return b.Where(Where{Type: "AND", Group: wheres})
+}
+
+// OrWhereGroup adds the conditions as a parenthesised group joined with OR
+func (b *Builder) OrWhereGroup(wheres ...Where) *Builder {
+	return b.Where(Where{Type: "OR", Group: wheres})
+}
+
 // columnsToSQL converts the columns statements to SQL.
 func (b *Builder) columnsToSQL(columns []map[string]any) string {
 	columnSqls := []string{}
@@ -626,6 +1123,68 @@ func (b *Builder) columnsToSQL(columns []map[string]any) string {
 				sql += " PRIMARY KEY"
 			}
 
+			// Non Nullable / Required
+			if columnNullable != "yes" {
+				sql += " NOT NULL"
+			}
+			return sql
+		}).ElseIfF(b.Dialect == DIALECT_MSSQL, func() string {
+			columnType := lo.
+				IfF(columnType == "string", func() string {
+					columnLength = lo.Ternary(columnLength == "", "255", columnLength)
+					return "NVARCHAR"
+				}).
+				ElseIfF(columnType == "integer", func() string {
+					return "BIGINT"
+				}).
+				ElseIfF(columnType == "float", func() string {
+					return "FLOAT"
+				}).
+				ElseIfF(columnType == "text" || columnType == "longtext", func() string {
+					columnLength = "MAX"
+					return "NVARCHAR"
+				}).
+				ElseIfF(columnType == "blob", func() string {
+					columnLength = "MAX"
+					return "VARBINARY"
+				}).
+				ElseIfF(columnType == "date", func() string {
+					return "DATE"
+				}).
+				ElseIfF(columnType == "datetime", func() string {
+					return "DATETIME2"
+				}).
+				ElseIfF(columnType == "decimal", func() string {
+					return "DECIMAL"
+				}).
+				Else(columnType)
+
+			sql := "[" + b.escapeMssqlIdentifier(columnName) + "] " + columnType
+
+			// Column length
+			if columnType == "DECIMAL" {
+				if columnLength == "" {
+					columnLength = "10"
+				}
+				if columnDecimals == "" {
+					columnDecimals = "2"
+				}
+				sql += "(" + columnLength + "," + columnDecimals + ")"
+
+			} else if columnLength != "" {
+				sql += "(" + columnLength + ")"
+			}
+
+			// Auto increment
+			if columnAuto == "yes" {
+				sql += " IDENTITY(1,1)"
+			}
+
+			// Primary key
+			if columnPrimary == "yes" {
+				sql += " PRIMARY KEY"
+			}
+
 			// Non Nullable / Required
 			if columnNullable != "yes" {
 				sql += " NOT NULL"
@@ -641,45 +1200,178 @@ func (b *Builder) columnsToSQL(columns []map[string]any) string {
 	return strings.Join(columnSqls, ", ")
 }
 
-func (b *Builder) whereToSqlSingle(column string, operator string, value string) string {
-	if operator == "==" || operator == "===" {
-		operator = "="
+// returningToSql converts the returning columns to the RETURNING clause of
+// Postgres and SQLite
+func (b *Builder) returningToSql() string {
+	if len(b.sqlReturning) < 1 || (b.Dialect != DIALECT_POSTGRES && b.Dialect != DIALECT_SQLITE) {
+		return ""
 	}
-	if operator == "!=" || operator == "!==" {
-		operator = "<>"
+
+	columns := lo.Map(b.sqlReturning, func(columnName string, _ int) string {
+		return b.quoteColumn(columnName)
+	})
+
+	return " RETURNING " + strings.Join(columns, ", ")
+}
+
+// outputToSql converts the returning columns to the OUTPUT clause of SQL
+// Server, reading them from the INSERTED or DELETED pseudo table
+func (b *Builder) outputToSql(pseudoTable string) string {
+	if len(b.sqlReturning) < 1 || b.Dialect != DIALECT_MSSQL {
+		return ""
+	}
+
+	columns := lo.Map(b.sqlReturning, func(columnName string, _ int) string {
+		return pseudoTable + "." + b.quoteColumn(columnName)
+	})
+
+	return " OUTPUT " + strings.Join(columns, ", ")
+}
+
+// selectLimitToSql converts the limit and offset of a SELECT to SQL. SQL
+// Server uses TOP, or OFFSET ... FETCH when there is an offset, returned
+// as the part after the SELECT keyword and the part ending the statement
+func (b *Builder) selectLimitToSql() (string, string) {
+	limit := strconv.FormatInt(b.sqlLimit, 10)
+	offset := strconv.FormatInt(b.sqlOffset, 10)
+
+	if b.Dialect == DIALECT_MSSQL {
+		if b.sqlOffset > 0 {
+			fetch := lo.Ternary(b.sqlLimit > 0, " FETCH NEXT "+limit+" ROWS ONLY", "")
+			return "", " OFFSET " + offset + " ROWS" + fetch
+		}
+		if b.sqlLimit > 0 {
+			return "TOP " + limit + " ", ""
+		}
+		return "", ""
 	}
-	columnQuoted := b.quoteColumn(column)
-	valueQuoted := b.quoteValue(value)
 
 	sql := ""
-	if b.Dialect == DIALECT_MYSQL {
-		if value == "NULL" && operator == "=" {
-			sql = columnQuoted + " IS NULL"
-		} else if value == "NULL" && operator == "<>" {
-			sql = columnQuoted + " IS NOT NULL"
-		} else {
-			sql = columnQuoted + " " + operator + " " + valueQuoted
+	if b.sqlLimit > 0 {
+		sql += " LIMIT " + limit
+	}
+
+	if b.sqlOffset > 0 {
+		// MySQL and SQLite do not allow an OFFSET without a LIMIT
+		if b.sqlLimit <= 0 && b.Dialect == DIALECT_MYSQL {
+			sql += " LIMIT 18446744073709551615"
+		}
+		if b.sqlLimit <= 0 && b.Dialect == DIALECT_SQLITE {
+			sql += " LIMIT -1"
 		}
+		sql += " OFFSET " + offset
 	}
+
+	return "", sql
+}
+
+// modifyLimitToSql converts the limit and offset of an UPDATE or DELETE to
+// SQL, as the part after the statement keyword and the part ending the
+// statement. Panics when the dialect can not limit the statement, or order
+// it as SQL Server, where TOP picks the rows in no particular order
+func (b *Builder) modifyLimitToSql(method string) (string, string) {
+	if b.Dialect == DIALECT_MSSQL && len(b.sqlOrderBy) > 0 {
+		panic("In method " + method + "() ORDER BY is not supported by " + b.Dialect + "!")
+	}
+
+	if b.sqlLimit <= 0 && b.sqlOffset <= 0 {
+		return "", ""
+	}
+
+	limit := strconv.FormatInt(b.sqlLimit, 10)
+	offset := strconv.FormatInt(b.sqlOffset, 10)
+
 	if b.Dialect == DIALECT_POSTGRES {
-		if value == "NULL" && operator == "=" {
-			sql = columnQuoted + " IS NULL"
-		} else if value == "NULL" && operator == "<>" {
-			sql = columnQuoted + " IS NOT NULL"
-		} else {
-			sql = columnQuoted + " " + operator + " " + valueQuoted
+		panic("In method " + method + "() LIMIT and OFFSET are not supported by " + b.Dialect + "!")
+	}
+
+	if b.sqlOffset > 0 && (b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_MSSQL) {
+		panic("In method " + method + "() OFFSET is not supported by " + b.Dialect + "!")
+	}
+
+	if b.Dialect == DIALECT_MSSQL {
+		return " TOP (" + limit + ")", ""
+	}
+
+	// SQLite requires to be compiled with SQLITE_ENABLE_UPDATE_DELETE_LIMIT
+	if b.Dialect == DIALECT_SQLITE && b.sqlOffset > 0 {
+		return "", " LIMIT " + lo.Ternary(b.sqlLimit > 0, limit, "-1") + " OFFSET " + offset
+	}
+
+	return "", " LIMIT " + limit
+}
+
+func (b *Builder) whereToSqlSingle(where Where) string {
+	operator := strings.ToUpper(strings.TrimSpace(where.Operator))
+	if operator == "==" || operator == "===" {
+		operator = "="
+	}
+	if operator == "!=" || operator == "!==" {
+		operator = "<>"
+	}
+	if operator == OPERATOR_EXISTS || operator == OPERATOR_NOT_EXISTS {
+		if where.Subquery == nil {
+			panic("In method Where() operator " + operator + " requires a subquery!")
 		}
+		return operator + " (" + b.subqueryToSql(where.Subquery) + ")"
 	}
-	if b.Dialect == DIALECT_SQLITE {
-		if value == "NULL" && operator == "=" {
-			sql = columnQuoted + " IS NULL"
-		} else if value == "NULL" && operator == "<>" {
-			sql = columnQuoted + " IS NOT NULL"
-		} else {
-			sql = columnQuoted + " " + operator + " " + valueQuoted
+
+	columnQuoted := b.quoteColumn(where.Column)
+	if where.Aggregate != "" {
+		columnQuoted = b.aggregateToSql(where.Aggregate, where.Column)
+	}
+
+	if where.Subquery != nil {
+		return columnQuoted + " " + operator + " (" + b.subqueryToSql(where.Subquery) + ")"
+	}
+
+	switch operator {
+	case OPERATOR_IS_NULL, OPERATOR_IS_NOT_NULL:
+		return columnQuoted + " " + operator
+	case OPERATOR_IN, OPERATOR_NOT_IN:
+		if len(where.Values) < 1 {
+			// Nothing is IN an empty list, everything is NOT IN it
+			return lo.Ternary(operator == OPERATOR_IN, "1 = 0", "1 = 1")
 		}
+		values := lo.Map(where.Values, func(value string, _ int) string {
+			return b.bindValue(value)
+		})
+		return columnQuoted + " " + operator + " (" + strings.Join(values, ", ") + ")"
+	case OPERATOR_BETWEEN, OPERATOR_NOT_BETWEEN:
+		if len(where.Values) != 2 {
+			panic("In method Where() operator " + operator + " requires exactly 2 values, " + strconv.Itoa(len(where.Values)) + " given!")
+		}
+		return columnQuoted + " " + operator + " " + b.bindValue(where.Values[0]) + " AND " + b.bindValue(where.Values[1])
+	case OPERATOR_LIKE, OPERATOR_NOT_LIKE:
+		return columnQuoted + " " + operator + " " + b.bindValue(b.whereValue(where)) + b.likeEscapeToSql()
+	case OPERATOR_ILIKE, OPERATOR_NOT_ILIKE:
+		if b.Dialect == DIALECT_POSTGRES {
+			return columnQuoted + " " + operator + " " + b.bindValue(b.whereValue(where))
+		}
+		// MySQL, SQLite and SQL Server have no ILIKE, both sides are lower cased instead
+		likeOperator := lo.Ternary(operator == OPERATOR_ILIKE, OPERATOR_LIKE, OPERATOR_NOT_LIKE)
+		return "LOWER(" + columnQuoted + ") " + likeOperator + " LOWER(" + b.bindValue(b.whereValue(where)) + ")" + b.likeEscapeToSql()
 	}
-	return sql
+
+	return columnQuoted + " " + operator + " " + b.bindValue(b.whereValue(where))
+}
+
+// whereValue returns the value the condition compares against
+func (b *Builder) whereValue(where Where) any {
+	if where.TypedValue != nil {
+		return where.TypedValue
+	}
+	return where.Value
+}
+
+// likeEscapeToSql returns the ESCAPE clause making the backslash escape the
+// LIKE wildcards, as done by EscapeLike. MySQL and Postgres use the backslash
+// by default, SQLite and SQL Server have no default escape character
+func (b *Builder) likeEscapeToSql() string {
+	if b.Dialect == DIALECT_SQLITE || b.Dialect == DIALECT_MSSQL {
+		return ` ESCAPE '\'`
+	}
+	return ""
 }
 
 /**
@@ -688,56 +1380,76 @@ func (b *Builder) whereToSqlSingle(column string, operator string, value string)
  * @return string
  */
 func (b *Builder) whereToSql(wheres []Where) string {
+	sql := b.whereListToSql(wheres)
+
+	if sql != "" {
+		return " WHERE " + sql
+	}
+
+	return ""
+}
+
+// whereListToSql converts a list of wheres to SQL, recursing into groups
+func (b *Builder) whereListToSql(wheres []Where) string {
 	sql := []string{}
 	for _, where := range wheres {
-		if where.Raw != "" {
-			sql = append(sql, where.Raw)
-			continue
-		}
-
 		if where.Type == "" {
 			where.Type = "AND"
 		}
 
-		if where.Column != "" {
-			sqlSingle := b.whereToSqlSingle(where.Column, where.Operator, where.Value)
+		sqlSingle := ""
 
-			if len(sql) > 0 {
-				sql = append(sql, where.Type+" "+sqlSingle)
-			} else {
-				sql = append(sql, sqlSingle)
+		if where.Raw != "" {
+			sqlSingle = where.Raw
+		} else if len(where.Group) > 0 {
+			sqlGroup := b.whereListToSql(where.Group)
+			if sqlGroup != "" {
+				sqlSingle = "(" + sqlGroup + ")"
 			}
+		} else if where.Column != "" || where.Subquery != nil {
+			sqlSingle = b.whereToSqlSingle(where)
+		}
+
+		if sqlSingle == "" {
+			continue
+		}
 
+		if len(sql) > 0 {
+			sql = append(sql, where.Type+" "+sqlSingle)
+		} else {
+			sql = append(sql, sqlSingle)
 		}
-		// } else {
-		// 	$_sql = array();
-		// 	$all = $where['WHERE'];
-		// 	for ($k = 0; $k < count($all); $k++) {
-		// 		$w = $all[$k];
-		// 		$sqlSingle = $this->whereToSqlSingle($w['COLUMN'], $w['OPERATOR'], $w['VALUE']);
-		// 		if ($k == 0) {
-		// 			$_sql[] = $sqlSingle;
-		// 		} else {
-		// 			$_sql[] = $w['TYPE'] . " " . $sqlSingle;
-		// 		}
-		// 	}
-		// 	$_sql = (count($_sql) > 0) ? " (" . implode(" ", $_sql) . ")" : "";
-
-		// 	if ($i == 0) {
-		// 		$sql[] = $_sql;
-		// 	} else {
-		// 		$sql[] = $where['TYPE'] . " " . $_sql;
-		// 	}
-		// }
 	}
 
-	if len(sql) > 0 {
-		return " WHERE " + strings.Join(sql, " ")
+	return strings.Join(sql, " ")
+}
+
+func (b *Builder) havingToSql(havings []Where) string {
+	sql := b.whereListToSql(havings)
+
+	if sql != "" {
+		return " HAVING " + sql
 	}
 
 	return ""
 }
 
+// aggregateToSql wraps the quoted column in the aggregate function. Only
+// the known AGGREGATE_* functions are accepted
+func (b *Builder) aggregateToSql(aggregate string, column string) string {
+	aggregate = strings.ToUpper(aggregate)
+	columnQuoted := b.quoteColumn(column)
+
+	switch aggregate {
+	case AGGREGATE_COUNT_DISTINCT:
+		return "COUNT(DISTINCT " + columnQuoted + ")"
+	case AGGREGATE_AVG, AGGREGATE_COUNT, AGGREGATE_MAX, AGGREGATE_MIN, AGGREGATE_SUM:
+		return aggregate + "(" + columnQuoted + ")"
+	}
+
+	panic("Aggregate function " + aggregate + " is not supported!")
+}
+
 func (b *Builder) groupByToSql(groupBys []GroupBy) string {
 	sql := []string{}
 	for _, groupBy := range groupBys {
@@ -751,47 +1463,95 @@ func (b *Builder) groupByToSql(groupBys []GroupBy) string {
 	return ""
 }
 
-// /**
-//      * Joins tables to SQL.
-//      * @return String the join SQL string
-//      * @access private
-//      */
-// 	 private function join_to_sql($join, $table_name)
-// 	 {
-// 		 $sql = '';
-// 		 // MySQL
-// 		 if ($this->database_type == 'mysql') {
-// 			 foreach ($join as $what) {
-// 				 $type = $what[3] ?? '';
-// 				 $alias = $what[4] ?? '';
-// 				 $sql .= ' ' . $type . ' JOIN `' . $what[0] . '`';
-// 				 if ($alias != "") {
-// 					 $sql .= ' AS ' . $alias . '';
-// 					 $what[0] = $alias;
-// 				 }
-// 				 if ($what[1] == $what[2]) {
-// 					 $sql .= ' USING (`' . $what[1] . '`)';
-// 				 } else {
-// 					 $sql .= ' ON ' . $table_name . '.' . $what[1] . '=' . $what[0] . '.' . $what[2];
-// 				 }
-// 			 }
-// 		 }
-// 		 // SQLite
-// 		 if ($this->database_type == 'sqlite' or $this->database_type == 'sqlitedb') {
-// 			 foreach ($join as $what) {
-// 				 $type = $what[3] ?? '';
-// 				 $alias = $what[4] ?? '';
-// 				 $sql .= " $type JOIN '" . $what[0] . "'";
-// 				 if ($alias != "") {
-// 					 $sql .= " AS '$alias'";
-// 					 $what[0] = $alias;
-// 				 }
-// 				 $sql .= ' ON ' . $table_name . '.' . $what[1] . '=' . $what[0] . '.' . $what[2];
-// 			 }
-// 		 }
+// joinToSql converts the joins to SQL
+func (b *Builder) joinToSql(joins []Join) string {
+	sql := ""
 
-// 		 return $sql;
-// 	 }
+	for _, join := range joins {
+		sql += " " + b.joinTypeToSql(join.Type) + " " + b.joinTableToSql(join)
+
+		// SQL Server has no USING, the columns are compared with ON instead
+		if len(join.Using) > 0 && b.Dialect != DIALECT_MSSQL {
+			usingColumns := lo.Map(join.Using, func(columnName string, _ int) string {
+				return b.quoteColumn(columnName)
+			})
+			sql += " USING (" + strings.Join(usingColumns, ", ") + ")"
+		} else if len(join.Using) > 0 || len(join.On) > 0 {
+			sql += " ON " + b.joinConditionsToSql(join)
+		}
+	}
+
+	return sql
+}
+
+// joinToUpdateFromSql converts the joins to the FROM part of an UPDATE for
+// the dialects supporting UPDATE ... FROM. The conditions of the first join
+// are moved to the WHERE clause, which is returned updated
+func (b *Builder) joinToUpdateFromSql(joins []Join, where string) (string, string) {
+	first := joins[0]
+	firstType := strings.ToUpper(first.Type)
+
+	if firstType != "" && firstType != JOIN_INNER && firstType != JOIN_CROSS {
+		panic("In method Update() the first join must be an inner or cross join, " + first.Type + " given!")
+	}
+
+	from := " FROM " + b.joinTableToSql(first) + b.joinToSql(joins[1:])
+
+	conditions := ""
+	if len(first.Using) > 0 || len(first.On) > 0 {
+		conditions = b.joinConditionsToSql(first)
+	}
+
+	if conditions == "" {
+		return from, where
+	}
+
+	if where == "" {
+		return from, " WHERE " + conditions
+	}
+
+	return from, " WHERE " + conditions + " AND (" + strings.TrimPrefix(where, " WHERE ") + ")"
+}
+
+func (b *Builder) joinTypeToSql(joinType string) string {
+	joinType = strings.ToUpper(joinType)
+	if joinType == "" {
+		return "JOIN"
+	}
+	return joinType + " JOIN"
+}
+
+func (b *Builder) joinTableToSql(join Join) string {
+	if join.Alias == "" {
+		return b.quoteTable(join.Table)
+	}
+	return b.quoteTable(join.Table) + " AS " + b.quoteTable(join.Alias)
+}
+
+// joinConditionsToSql converts the ON conditions of a join to SQL. USING
+// columns are expanded to equality conditions between the two tables
+func (b *Builder) joinConditionsToSql(join Join) string {
+	joinTable := lo.Ternary(join.Alias == "", join.Table, join.Alias)
+
+	conditions := []string{}
+
+	for _, columnName := range join.Using {
+		conditions = append(conditions, b.quoteColumn(b.sqlTableName+"."+columnName)+" = "+b.quoteColumn(joinTable+"."+columnName))
+	}
+
+	for _, on := range join.On {
+		operator := on.Operator
+		if operator == "" || operator == "==" || operator == "===" {
+			operator = "="
+		}
+		if operator == "!=" || operator == "!==" {
+			operator = "<>"
+		}
+		conditions = append(conditions, b.quoteColumn(on.Column)+" "+operator+" "+b.quoteColumn(on.OtherColumn))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
 
 func (b *Builder) orderByToSql(orderBys []OrderBy) string {
 	sql := []string{}
@@ -814,6 +1574,12 @@ func (b *Builder) orderByToSql(orderBys []OrderBy) string {
 		}
 	}
 
+	if b.Dialect == DIALECT_MSSQL {
+		for _, orderBy := range orderBys {
+			sql = append(sql, b.quoteColumn(orderBy.Column)+" "+orderBy.Direction)
+		}
+	}
+
 	if len(sql) > 0 {
 		return ` ORDER BY ` + strings.Join(sql, `,`)
 	}
@@ -848,6 +1614,10 @@ func (b *Builder) quoteColumn(columnName string) string {
 			columnPart = `"` + columnPart + `"`
 		}
 
+		if b.Dialect == DIALECT_MSSQL {
+			columnPart = "[" + b.escapeMssqlIdentifier(columnPart) + "]"
+		}
+
 		columnQuoted = append(columnQuoted, columnPart)
 	}
 
@@ -871,25 +1641,126 @@ func (b *Builder) quoteTable(tableName string) string {
 			tablePart = `"` + tablePart + `"`
 		}
 
+		if b.Dialect == DIALECT_MSSQL {
+			tablePart = "[" + b.escapeMssqlIdentifier(tablePart) + "]"
+		}
+
 		tableQuoted = append(tableQuoted, tablePart)
 	}
 
 	return strings.Join(tableQuoted, ".")
 }
 
+// bindValue renders a value for the statement being built. With parameters
+// enabled the value is collected and a placeholder is returned, otherwise
+// the value is rendered inline
+func (b *Builder) bindValue(value any) string {
+	if !b.sqlParamsOn {
+		return b.valueToSql(value)
+	}
+
+	b.sqlParams = append(b.sqlParams, value)
+
+	if b.Dialect == DIALECT_POSTGRES {
+		return "$" + strconv.Itoa(len(b.sqlParams))
+	}
+
+	if b.Dialect == DIALECT_MSSQL {
+		return "@p" + strconv.Itoa(len(b.sqlParams))
+	}
+
+	return "?"
+}
+
+// valueToSql renders a Go value inline as an SQL literal of the dialect:
+// NULL for nil, boolean and number literals, dialect formatted timestamps,
+// binary literals for []byte, and quoted strings for everything else
+func (b *Builder) valueToSql(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return b.quoteValue(v)
+	case bool:
+		if b.Dialect == DIALECT_MYSQL || b.Dialect == DIALECT_POSTGRES {
+			return lo.Ternary(v, "TRUE", "FALSE")
+		}
+		return lo.Ternary(v, "1", "0")
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return b.quoteValue(v.Format(b.timeFormat()))
+	case *time.Time:
+		if v == nil {
+			return "NULL"
+		}
+		return b.valueToSql(*v)
+	case []byte:
+		return b.binaryToSql(v)
+	case driver.Valuer:
+		// Decimals, nullable and custom types know their database value
+		if reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+			return "NULL"
+		}
+		dbValue, err := v.Value()
+		if err != nil {
+			panic("Value " + fmt.Sprint(v) + " can not be converted: " + err.Error())
+		}
+		return b.valueToSql(dbValue)
+	case fmt.Stringer:
+		return b.quoteValue(v.String())
+	}
+
+	return b.quoteValue(fmt.Sprint(value))
+}
+
+// timeFormat returns the layout of the timestamp literals of the dialect
+func (b *Builder) timeFormat() string {
+	if b.Dialect == DIALECT_POSTGRES {
+		return "2006-01-02 15:04:05.999999-07:00"
+	}
+	if b.Dialect == DIALECT_SQLITE {
+		// as written by the SQLite drivers
+		return "2006-01-02 15:04:05.999999999-07:00"
+	}
+	if b.Dialect == DIALECT_MSSQL {
+		return "2006-01-02 15:04:05.9999999"
+	}
+	return "2006-01-02 15:04:05.999999"
+}
+
+// binaryToSql renders the bytes as a binary literal of the dialect
+func (b *Builder) binaryToSql(value []byte) string {
+	if b.Dialect == DIALECT_POSTGRES {
+		return `'\x` + hex.EncodeToString(value) + `'`
+	}
+	if b.Dialect == DIALECT_MSSQL {
+		return "0x" + hex.EncodeToString(value)
+	}
+	return "X'" + hex.EncodeToString(value) + "'"
+}
+
 func (b *Builder) quoteValue(value string) string {
 	if b.Dialect == DIALECT_MYSQL {
 		value = `"` + b.escapeMysql(value) + `"`
 	}
 
 	if b.Dialect == DIALECT_POSTGRES {
-		value = `"` + b.escapePostgres(value) + `"`
+		value = `'` + b.escapePostgres(value) + `'`
 	}
 
 	if b.Dialect == DIALECT_SQLITE {
 		value = `'` + b.escapeSqlite(value) + `'`
 	}
 
+	if b.Dialect == DIALECT_MSSQL {
+		value = `N'` + b.escapeMssql(value) + `'`
+	}
+
 	return value
 }
 
@@ -917,12 +1788,14 @@ func (b *Builder) escapeMysql(value string) string {
 	// 	return s
 	// })
 
-	escapedStr := strings.ReplaceAll(value, `"`, `""`)
-	return escapedStr
+	// The backslash is the escape character of the MySQL strings, it is
+	// doubled so it is kept, as the one added by EscapeLike
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `""`)
+	return replacer.Replace(value)
 }
 
 func (b *Builder) escapePostgres(value string) string {
-	escapedStr := strings.ReplaceAll(value, `"`, `""`)
+	escapedStr := strings.ReplaceAll(value, "'", "''")
 	return escapedStr
 }
 
@@ -931,6 +1804,16 @@ func (b *Builder) escapeSqlite(value string) string {
 	return escapedStr
 }
 
+func (b *Builder) escapeMssql(value string) string {
+	escapedStr := strings.ReplaceAll(value, "'", "''")
+	return escapedStr
+}
+
+func (b *Builder) escapeMssqlIdentifier(value string) string {
+	escapedStr := strings.ReplaceAll(value, "]", "]]")
+	return escapedStr
+}
+
 /**
  * The <b>tables</b> method returns the names of all the tables, that
  * exist in the database.
@@ -0,0 +1,189 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"github.com/georgysavva/scany/dbscan"
+)
+
+// structField is a column mapped from a struct field with the db tag
+//
+//	db:"column,pk,readonly,omitempty"
+//
+// untagged exported fields use the snake case of the field name, as scany
+// does when scanning, and db:"-" skips the field
+type structField struct {
+	Column    string
+	Value     any
+	IsZero    bool
+	PK        bool
+	ReadOnly  bool
+	OmitEmpty bool
+}
+
+// InsertStruct returns an INSERT of the struct (or pointer to struct) fields.
+// Read-only fields, and omitempty fields with a zero value, are left out
+func (b *Builder) InsertStruct(model any) string {
+	columnValues := map[string]any{}
+	for _, field := range structFields("InsertStruct", model) {
+		if field.ReadOnly || (field.OmitEmpty && field.IsZero) {
+			continue
+		}
+		columnValues[field.Column] = field.Value
+	}
+
+	if len(columnValues) < 1 {
+		panic("In method InsertStruct() the model has no columns to insert!")
+	}
+
+	return b.Insert(columnValues)
+}
+
+// UpdateStruct returns an UPDATE of the struct (or pointer to struct) fields
+// of the row with the primary key of the model. Primary key and read-only
+// fields, and omitempty fields with a zero value, are not set. The where
+// conditions of the builder are added to the primary key
+func (b *Builder) UpdateStruct(model any) string {
+	columnValues := map[string]any{}
+	pkWheres := []Where{}
+	for _, field := range structFields("UpdateStruct", model) {
+		if field.PK {
+			if field.Value == nil {
+				panic("In method UpdateStruct() the primary key " + field.Column + " is nil!")
+			}
+			pkWheres = append(pkWheres, Where{Column: field.Column, Operator: "=", TypedValue: field.Value})
+			continue
+		}
+		if field.ReadOnly || (field.OmitEmpty && field.IsZero) {
+			continue
+		}
+		columnValues[field.Column] = field.Value
+	}
+
+	if len(pkWheres) < 1 {
+		panic("In method UpdateStruct() the model has no primary key field, tag one with db:\"column,pk\"!")
+	}
+
+	if len(columnValues) < 1 {
+		panic("In method UpdateStruct() the model has no columns to update!")
+	}
+
+	// The primary key conditions apply to this statement only
+	wheres := b.sqlWhere
+	b.sqlWhere = append(append([]Where{}, pkWheres...), wheres...)
+	defer func() { b.sqlWhere = wheres }()
+
+	return b.Update(columnValues)
+}
+
+// SelectStruct returns a SELECT of the columns of the struct (or pointer to
+// struct) fields, read-only ones included, in field order
+func (b *Builder) SelectStruct(model any) string {
+	columns := []string{}
+	for _, field := range structFields("SelectStruct", model) {
+		columns = append(columns, field.Column)
+	}
+
+	return b.Select(columns)
+}
+
+// structFields returns the columns of the struct fields in field order,
+// embedded structs are flattened
+func structFields(method string, model any) []structField {
+	value := reflect.ValueOf(model)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			panic("In method " + method + "() the model is a nil pointer!")
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		panic("In method " + method + "() the model must be a struct or a pointer to struct, " + value.Kind().String() + " given!")
+	}
+
+	return structFieldsOf(value)
+}
+
+func structFieldsOf(value reflect.Value) []structField {
+	fields := []structField{}
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		fieldValue := value.Field(i)
+
+		// The exported fields of unexported embedded structs are still promoted
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag, tagPresent := field.Tag.Lookup("db")
+		tagParts := strings.Split(tag, ",")
+		column := strings.TrimSpace(tagParts[0])
+		if column == "-" {
+			continue
+		}
+
+		// Untagged embedded structs contribute their own fields
+		if field.Anonymous && column == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, structFieldsOf(embedded)...)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if !tagPresent || column == "" {
+			column = dbscan.SnakeCaseMapper(field.Name)
+		}
+
+		mapped := structField{
+			Column: column,
+			Value:  structFieldValue(fieldValue),
+			IsZero: fieldValue.IsZero(),
+		}
+
+		for _, option := range tagParts[1:] {
+			switch strings.TrimSpace(option) {
+			case "pk":
+				mapped.PK = true
+			case "readonly":
+				mapped.ReadOnly = true
+			case "omitempty":
+				mapped.OmitEmpty = true
+			}
+		}
+
+		fields = append(fields, mapped)
+	}
+
+	return fields
+}
+
+// structFieldValue returns the value of the field to bind, nil pointers are
+// NULL and other pointers are dereferenced unless they are valuers
+func structFieldValue(fieldValue reflect.Value) any {
+	if fieldValue.Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			return nil
+		}
+		if _, isValuer := fieldValue.Interface().(driver.Valuer); !isValuer {
+			return fieldValue.Elem().Interface()
+		}
+	}
+
+	return fieldValue.Interface()
+}
@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+type testTimestamps struct {
+	CreatedAt time.Time `db:"created_at,readonly"`
+}
+
+type testUser struct {
+	ID        int64   `db:"id,pk,omitempty"`
+	FirstName string  `db:"first_name"`
+	Nickname  *string `db:"nickname"`
+	Email     string  `db:",omitempty"`
+	Status    string
+	Secret    string `db:"-"`
+	internal  string
+	testTimestamps
+}
+
+func TestBuilderInsertStruct(t *testing.T) {
+	user := testUser{FirstName: "Tom", Status: "active", Secret: "x", internal: "y"}
+
+	sql := NewBuilder(DIALECT_SQLITE).Table("users").InsertStruct(&user)
+
+	expected := `INSERT INTO "users" ("first_name", "nickname", "status") VALUES ('Tom', NULL, 'active');`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderInsertStructParams(t *testing.T) {
+	nickname := "tommy"
+	user := testUser{ID: 3, FirstName: "Tom", Nickname: &nickname, Email: "tom@test.com"}
+
+	builder := NewBuilder(DIALECT_POSTGRES).WithParams().Table("users")
+	sql := builder.InsertStruct(user)
+
+	expected := `INSERT INTO "users" ("email", "first_name", "id", "nickname", "status") VALUES ($1, $2, $3, $4, $5);`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 5 || params[2] != int64(3) || params[3] != "tommy" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderUpdateStruct(t *testing.T) {
+	user := testUser{ID: 5, FirstName: "Tom", Status: "active"}
+
+	builder := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Where(Where{Column: "status", Operator: "<>", Value: "deleted"})
+
+	sql := builder.UpdateStruct(user)
+
+	expected := "UPDATE `users` SET `first_name`=\"Tom\", `nickname`=NULL, `status`=\"active\" WHERE `id` = 5 AND `status` <> \"deleted\";"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	// The primary key condition is not kept by the builder
+	if len(builder.sqlWhere) != 1 {
+		t.Fatal("Expected 1 where condition, found:", len(builder.sqlWhere))
+	}
+}
+
+func TestBuilderUpdateStructParams(t *testing.T) {
+	user := testUser{ID: 5, FirstName: "Tom", Status: "active"}
+
+	builder := NewBuilder(DIALECT_POSTGRES).WithParams().Table("users")
+	sql := builder.UpdateStruct(user)
+
+	expected := `UPDATE "users" SET "first_name"=$1, "nickname"=$2, "status"=$3 WHERE "id" = $4;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	// The primary key is bound with its type, not as a string
+	params := builder.Params()
+	if len(params) != 4 || params[3] != int64(5) {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderUpdateStructValuerPrimaryKey(t *testing.T) {
+	type account struct {
+		ID   sql.NullInt64 `db:"id,pk"`
+		Name string        `db:"name"`
+	}
+
+	model := account{ID: sql.NullInt64{Int64: 5, Valid: true}, Name: "Tom"}
+
+	sql := NewBuilder(DIALECT_SQLITE).Table("accounts").UpdateStruct(model)
+
+	expected := `UPDATE "accounts" SET "name"='Tom' WHERE "id" = 5;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderUpdateStructWithoutPrimaryKeyPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected a panic for a model without primary key")
+		}
+	}()
+
+	NewBuilder(DIALECT_SQLITE).Table("users").UpdateStruct(struct {
+		Name string `db:"name"`
+	}{Name: "Tom"})
+}
+
+func TestBuilderSelectStruct(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).Table("users").SelectStruct(&testUser{})
+
+	expected := `SELECT "id", "first_name", "nickname", "email", "status", "created_at" FROM "users";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderInsertStructNotStructPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected a panic for a model which is not a struct")
+		}
+	}()
+
+	NewBuilder(DIALECT_SQLITE).Table("users").InsertStruct(map[string]any{"name": "Tom"})
+}
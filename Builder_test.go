@@ -1,7 +1,9 @@
 package sql
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 	// _ "github.com/glebarez/go-sqlite"
 	// _ "github.com/mattn/go-sqlite3"
 )
@@ -305,10 +307,9 @@ func TestBuilderTableDeleteMysqlExtended(t *testing.T) {
 			Type:     "OR",
 		}).
 		Limit(12).
-		Offset(34).
 		Delete()
 
-	expected := "DELETE FROM `users` WHERE `FirstName` = \"Tom\" OR `FirstName` = \"Sam\" LIMIT 12 OFFSET 34;"
+	expected := "DELETE FROM `users` WHERE `FirstName` = \"Tom\" OR `FirstName` = \"Sam\" LIMIT 12;"
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -406,7 +407,7 @@ func TestBuilderTableSelectFullPostgres(t *testing.T) {
 		GroupBy(GroupBy{Column: "passport"}).
 		Select([]string{"id", "first_name", "last_name"})
 
-	expected := `SELECT "id", "first_name", "last_name" FROM "users" WHERE "first_name" <> "Jane" GROUP BY "passport" ORDER BY "first_name" ASC LIMIT 10 OFFSET 20;`
+	expected := `SELECT "id", "first_name", "last_name" FROM "users" WHERE "first_name" <> 'Jane' GROUP BY "passport" ORDER BY "first_name" ASC LIMIT 10 OFFSET 20;`
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -432,12 +433,12 @@ func TestBuilderTableInsertMysql(t *testing.T) {
 	sql := NewBuilder(DIALECT_MYSQL).
 		Table("users").
 		Limit(1).
-		Insert(map[string]string{
+		Insert(map[string]any{
 			"first_name": "Tom",
 			"last_name":  "Jones",
 		})
 
-	expected := "INSERT INTO `users` (`first_name`, `last_name`) VALUES (\"Tom\", \"Jones\") LIMIT 1;"
+	expected := "INSERT INTO `users` (`first_name`, `last_name`) VALUES (\"Tom\", \"Jones\");"
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -447,12 +448,12 @@ func TestBuilderTableInsertPostgres(t *testing.T) {
 	sql := NewBuilder(DIALECT_POSTGRES).
 		Table("users").
 		Limit(1).
-		Insert(map[string]string{
+		Insert(map[string]any{
 			"first_name": "Tom",
 			"last_name":  "Jones",
 		})
 
-	expected := `INSERT INTO "users" ("first_name", "last_name") VALUES ("Tom", "Jones") LIMIT 1;`
+	expected := `INSERT INTO "users" ("first_name", "last_name") VALUES ('Tom', 'Jones');`
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -462,12 +463,12 @@ func TestBuilderTableInsertSqlite(t *testing.T) {
 	sql := NewBuilder(DIALECT_SQLITE).
 		Table("users").
 		Limit(1).
-		Insert(map[string]string{
+		Insert(map[string]any{
 			"first_name": "Tom",
 			"last_name":  "Jones",
 		})
 
-	expected := `INSERT INTO "users" ("first_name", "last_name") VALUES ('Tom', 'Jones') LIMIT 1;`
+	expected := `INSERT INTO "users" ("first_name", "last_name") VALUES ('Tom', 'Jones');`
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -482,7 +483,7 @@ func TestBuilderTableUpdateMysql(t *testing.T) {
 			Value:    "1",
 		}).
 		Limit(1).
-		Update(map[string]string{
+		Update(map[string]any{
 			"first_name": "Tom",
 			"last_name":  "Jones",
 		})
@@ -501,13 +502,12 @@ func TestBuilderTableUpdatePostgres(t *testing.T) {
 			Operator: "==",
 			Value:    "1",
 		}).
-		Limit(1).
-		Update(map[string]string{
+		Update(map[string]any{
 			"first_name": "Tom",
 			"last_name":  "Jones",
 		})
 
-	expected := `UPDATE "users" SET "first_name"="Tom", "last_name"="Jones" WHERE "id" = "1" LIMIT 1;`
+	expected := `UPDATE "users" SET "first_name"='Tom', "last_name"='Jones' WHERE "id" = '1';`
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -522,7 +522,7 @@ func TestBuilderTableUpdateSqlite(t *testing.T) {
 			Value:    "1",
 		}).
 		Limit(1).
-		Update(map[string]string{
+		Update(map[string]any{
 			"first_name": "Tom",
 			"last_name":  "Jones",
 		})
@@ -548,10 +548,10 @@ func TestBuilderTableSelectMysqlInj(t *testing.T) {
 func TestBuilderTableSelectPostgreslInj(t *testing.T) {
 	sql := NewBuilder(DIALECT_POSTGRES).
 		Table("users").
-		Where(Where{Column: "id", Operator: "=", Value: "58\" OR 1 = 1;--"}).
+		Where(Where{Column: "id", Operator: "=", Value: "58' OR 1 = 1;--"}).
 		Select([]string{})
 
-	expected := `SELECT * FROM "users" WHERE "id" = "58"" OR 1 = 1;--";`
+	expected := `SELECT * FROM "users" WHERE "id" = '58'' OR 1 = 1;--';`
 	if sql != expected {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
@@ -590,3 +590,1171 @@ func TestBuilderTableSelectFn(t *testing.T) {
 		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
 	}
 }
+
+func TestBuilderTableSelectParamsMysql(t *testing.T) {
+	builder := NewBuilder(DIALECT_MYSQL).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "first_name", Operator: "==", Value: "Tom"}).
+		Where(Where{Column: "last_name", Operator: "!=", Value: "58\" OR 1 = 1;--"})
+
+	sql := builder.Select([]string{"id"})
+
+	expected := "SELECT `id` FROM `users` WHERE `first_name` = ? AND `last_name` <> ?;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 2 || params[0] != "Tom" || params[1] != "58\" OR 1 = 1;--" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableSelectParamsPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "first_name", Operator: "==", Value: "Tom"}).
+		Where(Where{Column: "last_name", Operator: "==", Value: "Jones", Type: "OR"})
+
+	sql := builder.Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE "first_name" = $1 OR "last_name" = $2;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 2 || params[0] != "Tom" || params[1] != "Jones" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableInsertParamsSqlite(t *testing.T) {
+	builder := NewBuilder(DIALECT_SQLITE).
+		WithParams().
+		Table("users")
+
+	sql := builder.Insert(map[string]any{
+		"first_name": "Tom",
+		"last_name":  "O'Jones",
+	})
+
+	expected := `INSERT INTO "users" ("first_name", "last_name") VALUES (?, ?);`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 2 || params[0] != "Tom" || params[1] != "O'Jones" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableUpdateParamsPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "id", Operator: "==", Value: "1"})
+
+	sql := builder.Update(map[string]any{
+		"first_name": "Tom",
+		"last_name":  "Jones",
+	})
+
+	expected := `UPDATE "users" SET "first_name"=$1, "last_name"=$2 WHERE "id" = $3;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 3 || params[0] != "Tom" || params[1] != "Jones" || params[2] != "1" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableDeleteParamsPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "id", Operator: "==", Value: "1"})
+
+	sql := builder.Delete()
+
+	expected := `DELETE FROM "users" WHERE "id" = $1;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	// Params are reset for every generated statement
+	builder.Delete()
+	if len(builder.Params()) != 1 {
+		t.Fatal("Unexpected params:", builder.Params())
+	}
+}
+
+func TestBuilderTableSelectJoinMysql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		LeftJoin(Join{
+			Table: "orders",
+			Alias: "o",
+			On:    []JoinOn{{Column: "users.id", Operator: "==", OtherColumn: "o.user_id"}},
+		}).
+		InnerJoin(Join{
+			Table: "profiles",
+			Using: []string{"profile_id"},
+		}).
+		CrossJoin(Join{Table: "currencies"}).
+		Select([]string{"users.id", "o.total"})
+
+	expected := "SELECT `users`.`id`, `o`.`total` FROM `users` LEFT JOIN `orders` AS `o` ON `users`.`id` = `o`.`user_id` INNER JOIN `profiles` USING (`profile_id`) CROSS JOIN `currencies`;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectJoinPostgres(t *testing.T) {
+	sql := NewBuilder(DIALECT_POSTGRES).
+		Table("users").
+		Join(Join{
+			Table: "orders",
+			On: []JoinOn{
+				{Column: "users.id", OtherColumn: "orders.user_id"},
+				{Column: "users.country", OtherColumn: "orders.country"},
+			},
+		}).
+		RightJoin(Join{
+			Table: "payments",
+			Alias: "p",
+			On:    []JoinOn{{Column: "orders.id", Operator: "=", OtherColumn: "p.order_id"}},
+		}).
+		Where(Where{Column: "users.id", Operator: "=", Value: "1"}).
+		Select([]string{"orders.*"})
+
+	expected := `SELECT "orders".* FROM "users" JOIN "orders" ON "users"."id" = "orders"."user_id" AND "users"."country" = "orders"."country" RIGHT JOIN "payments" AS "p" ON "orders"."id" = "p"."order_id" WHERE "users"."id" = '1';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectJoinMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		InnerJoin(Join{
+			Table: "profiles",
+			Alias: "p",
+			Using: []string{"profile_id", "tenant_id"},
+		}).
+		LeftJoin(Join{
+			Table: "orders",
+			On:    []JoinOn{{Column: "users.id", OtherColumn: "orders.user_id"}},
+		}).
+		Select([]string{"users.id", "p.bio"})
+
+	expected := "SELECT [users].[id], [p].[bio] FROM [users] INNER JOIN [profiles] AS [p] ON [users].[profile_id] = [p].[profile_id] AND [users].[tenant_id] = [p].[tenant_id] LEFT JOIN [orders] ON [users].[id] = [orders].[user_id];"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpdateJoinMysql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		InnerJoin(Join{
+			Table: "orders",
+			Alias: "o",
+			On:    []JoinOn{{Column: "users.id", OtherColumn: "o.user_id"}},
+		}).
+		Where(Where{Column: "o.status", Operator: "=", Value: "paid"}).
+		Update(map[string]any{
+			"users.status": "customer",
+		})
+
+	expected := "UPDATE `users` INNER JOIN `orders` AS `o` ON `users`.`id` = `o`.`user_id` SET `users`.`status`=\"customer\" WHERE `o`.`status` = \"paid\";"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpdateJoinSqlite(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		InnerJoin(Join{
+			Table: "orders",
+			Alias: "o",
+			On:    []JoinOn{{Column: "users.id", OtherColumn: "o.user_id"}},
+		}).
+		Where(Where{Column: "o.status", Operator: "=", Value: "paid"}).
+		Where(Where{Column: "o.status", Operator: "=", Value: "sent", Type: "OR"}).
+		Update(map[string]any{
+			"status": "customer",
+		})
+
+	expected := `UPDATE "users" SET "status"='customer' FROM "orders" AS "o" WHERE "users"."id" = "o"."user_id" AND ("o"."status" = 'paid' OR "o"."status" = 'sent');`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpdateJoinPostgresUsing(t *testing.T) {
+	sql := NewBuilder(DIALECT_POSTGRES).
+		Table("users").
+		Join(Join{
+			Table: "profiles",
+			Using: []string{"profile_id"},
+		}).
+		Update(map[string]any{
+			"status": "verified",
+		})
+
+	expected := `UPDATE "users" SET "status"='verified' FROM "profiles" WHERE "users"."profile_id" = "profiles"."profile_id";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpdateLeftJoinPostgresPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Update with a first LEFT JOIN on Postgres MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_POSTGRES).
+		Table("users").
+		LeftJoin(Join{Table: "orders", On: []JoinOn{{Column: "users.id", OtherColumn: "orders.user_id"}}}).
+		Update(map[string]any{"status": "customer"})
+}
+
+func TestBuilderTableSelectWhereGroupSqlite(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Where(Where{Column: "a", Operator: "=", Value: "1"}).
+		WhereGroup(
+			Where{Column: "b", Operator: "=", Value: "2"},
+			Where{Column: "c", Operator: "=", Value: "3", Type: "OR"},
+		).
+		Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE "a" = '1' AND ("b" = '2' OR "c" = '3');`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectWhereGroupNestedPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "a", Operator: "=", Value: "1"}).
+		OrWhereGroup(
+			Where{Column: "b", Operator: "=", Value: "2"},
+			Where{Type: "OR", Group: []Where{
+				{Column: "c", Operator: "=", Value: "3"},
+				{Column: "d", Operator: "!=", Value: "4"},
+			}},
+		).
+		OrWhere(Where{Column: "e", Operator: "=", Value: "5"})
+
+	sql := builder.Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE "a" = $1 OR ("b" = $2 OR ("c" = $3 AND "d" <> $4)) OR "e" = $5;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 5 || params[2] != "3" || params[4] != "5" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableSelectWhereRawSqlite(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Where(Where{Column: "a", Operator: "=", Value: "1"}).
+		Where(Where{Raw: `"b" = 2`}).
+		OrWhereGroup(
+			Where{Raw: `"c" > 3`},
+			Where{Column: "d", Operator: "=", Value: "4"},
+			Where{Raw: `"e" IS NULL`, Type: "OR"},
+		).
+		Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE "a" = '1' AND "b" = 2 OR ("c" > 3 AND "d" = '4' OR "e" IS NULL);`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableDeleteWhereGroupFirstMysql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		WhereGroup(
+			Where{Column: "a", Operator: "=", Value: "1"},
+			Where{Column: "b", Operator: "=", Value: "2", Type: "OR"},
+		).
+		WhereGroup().
+		Where(Where{Column: "c", Operator: "=", Value: "3"}).
+		Delete()
+
+	expected := "DELETE FROM `users` WHERE (`a` = \"1\" OR `b` = \"2\") AND `c` = \"3\";"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectWhereOperatorsMysql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Where(Where{Column: "id", Operator: OPERATOR_IN, Values: []string{"1", "2"}}).
+		Where(Where{Column: "status", Operator: "not in", Values: []string{"deleted"}}).
+		Where(Where{Column: "age", Operator: OPERATOR_BETWEEN, Values: []string{"18", "65"}}).
+		Where(Where{Column: "name", Operator: OPERATOR_ILIKE, Value: "tom%"}).
+		Where(Where{Column: "deleted_at", Operator: OPERATOR_IS_NULL}).
+		Select([]string{})
+
+	expected := "SELECT * FROM `users` WHERE `id` IN (\"1\", \"2\") AND `status` NOT IN (\"deleted\") AND `age` BETWEEN \"18\" AND \"65\" AND LOWER(`name`) LIKE LOWER(\"tom%\") AND `deleted_at` IS NULL;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectWhereOperatorsPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "id", Operator: OPERATOR_NOT_BETWEEN, Values: []string{"10", "20"}}).
+		Where(Where{Column: "name", Operator: OPERATOR_NOT_ILIKE, Value: "tom%"}).
+		Where(Where{Column: "email", Operator: OPERATOR_NOT_LIKE, Value: "%" + EscapeLike("_test") + "%"}).
+		Where(Where{Column: "deleted_at", Operator: OPERATOR_IS_NOT_NULL}).
+		Where(Where{Column: "nickname", Operator: "=", Value: "NULL"})
+
+	sql := builder.Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE "id" NOT BETWEEN $1 AND $2 AND "name" NOT ILIKE $3 AND "email" NOT LIKE $4 AND "deleted_at" IS NOT NULL AND "nickname" = $5;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 5 || params[3] != `%\_test%` || params[4] != "NULL" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableSelectWhereOperatorsSqlite(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Where(Where{Column: "id", Operator: OPERATOR_IN, Values: []string{}}).
+		Where(Where{Column: "role", Operator: OPERATOR_NOT_IN, Values: []string{}}).
+		Where(Where{Column: "name", Operator: OPERATOR_LIKE, Value: EscapeLike("50%") + "%"}).
+		Where(Where{Column: "city", Operator: OPERATOR_ILIKE, Value: "lon%"}).
+		Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE 1 = 0 AND 1 = 1 AND "name" LIKE '50\%%' ESCAPE '\' AND LOWER("city") LIKE LOWER('lon%') ESCAPE '\';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectWhereTypedValue(t *testing.T) {
+	createdAt := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "score", Operator: ">=", TypedValue: 10}).
+		Where(Where{Column: "created_at", Operator: "<", TypedValue: createdAt}).
+		Where(Where{Column: "parent_id", Operator: "=", TypedValue: sql.NullInt64{Int64: 3, Valid: true}})
+
+	query := builder.Select([]string{})
+
+	expected := `SELECT * FROM "users" WHERE "score" >= $1 AND "created_at" < $2 AND "parent_id" = $3;`
+	if query != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", query)
+	}
+
+	params := builder.Params()
+	if len(params) != 3 || params[0] != 10 || params[1] != createdAt || params[2] != (sql.NullInt64{Int64: 3, Valid: true}) {
+		t.Fatal("Unexpected params:", params)
+	}
+
+	query = NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Where(Where{Column: "score", Operator: ">=", TypedValue: 10}).
+		Where(Where{Column: "parent_id", Operator: "=", TypedValue: sql.NullInt64{Int64: 3, Valid: true}}).
+		Select([]string{})
+
+	expected = `SELECT * FROM "users" WHERE "score" >= 10 AND "parent_id" = 3;`
+	if query != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", query)
+	}
+}
+
+func TestBuilderTableSelectWhereLikeMysql(t *testing.T) {
+	// The backslashes of EscapeLike are kept by the MySQL string parser
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("files").
+		Where(Where{Column: "path", Operator: OPERATOR_LIKE, Value: EscapeLike(`a\b_1`) + "%"}).
+		Where(Where{Column: "name", Operator: "=", Value: `x\" OR 1 = 1;--`}).
+		Select([]string{})
+
+	expected := "SELECT * FROM `files` WHERE `path` LIKE \"a\\\\\\\\b\\\\_1%\" AND `name` = \"x\\\\\"\" OR 1 = 1;--\";"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectWhereBetweenPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("BETWEEN with a single value MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Where(Where{Column: "age", Operator: OPERATOR_BETWEEN, Values: []string{"18"}}).
+		Select([]string{})
+}
+
+func TestBuilderTableSelectWhereSubqueryPostgres(t *testing.T) {
+	orders := NewBuilder(DIALECT_POSTGRES).
+		Table("orders").
+		Where(Where{Column: "total", Operator: ">", Value: "100"}).
+		SelectColumns(SelectColumn{Column: "user_id"})
+
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "status", Operator: "=", Value: "active"}).
+		Where(Where{Column: "id", Operator: OPERATOR_IN, Subquery: orders}).
+		Where(Where{Column: "country", Operator: "=", Value: "NL"})
+
+	sql := builder.Select([]string{"id"})
+
+	expected := `SELECT "id" FROM "users" WHERE "status" = $1 AND "id" IN (SELECT "user_id" FROM "orders" WHERE "total" > $2) AND "country" = $3;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 3 || params[0] != "active" || params[1] != "100" || params[2] != "NL" {
+		t.Fatal("Unexpected params:", params)
+	}
+
+	if len(orders.Params()) != 0 || orders.sqlParamsOn {
+		t.Fatal("Subquery builder MUST NOT be changed")
+	}
+}
+
+func TestBuilderTableSelectWhereExistsMysql(t *testing.T) {
+	orders := NewBuilder(DIALECT_MYSQL).
+		Table("orders").
+		Where(Where{Raw: "`orders`.`user_id` = `users`.`id`"})
+
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Where(Where{Operator: OPERATOR_NOT_EXISTS, Subquery: orders}).
+		Select([]string{})
+
+	expected := "SELECT * FROM `users` WHERE NOT EXISTS (SELECT * FROM `orders` WHERE `orders`.`user_id` = `users`.`id`);"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectSubqueryColumnsAndFromSqlite(t *testing.T) {
+	orderCount := NewBuilder(DIALECT_SQLITE).
+		Table("orders").
+		Where(Where{Raw: `"orders"."user_id" = "u"."id"`}).
+		Where(Where{Column: "status", Operator: "=", Value: "paid"}).
+		SelectColumns(SelectColumn{Column: "COUNT(*)"})
+
+	activeUsers := NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Where(Where{Column: "status", Operator: "=", Value: "active"})
+
+	builder := NewBuilder(DIALECT_SQLITE).
+		WithParams().
+		FromSubquery(activeUsers, "u").
+		SelectColumns(SelectColumn{Subquery: orderCount, Alias: "order_count"}).
+		Where(Where{Column: "u.country", Operator: "=", Value: "NL"})
+
+	sql := builder.Select([]string{"u.id"})
+
+	expected := `SELECT "u"."id", (SELECT COUNT(*) FROM "orders" WHERE "orders"."user_id" = "u"."id" AND "status" = ?) AS "order_count" FROM (SELECT * FROM "users" WHERE "status" = ?) AS "u" WHERE "u"."country" = ?;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 3 || params[0] != "paid" || params[1] != "active" || params[2] != "NL" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableSelectHavingPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("orders").
+		Where(Where{Column: "status", Operator: "=", Value: "paid"}).
+		GroupBy(GroupBy{Column: "user_id"}).
+		Having(Where{Column: "*", Aggregate: AGGREGATE_COUNT, Operator: ">", TypedValue: 5}).
+		OrHaving(Where{Column: "total", Aggregate: AGGREGATE_SUM, Operator: ">=", TypedValue: 1000.0}).
+		SelectColumns(
+			Count("*", "order_count"),
+			CountDistinct("product_id", "products"),
+			Sum("total", "total"),
+			Avg("total", ""),
+			Min("created_at", "first_order"),
+			Max("created_at", "last_order"),
+		)
+
+	sql := builder.Select([]string{"user_id"})
+
+	expected := `SELECT "user_id", COUNT(*) AS "order_count", COUNT(DISTINCT "product_id") AS "products", SUM("total") AS "total", AVG("total"), MIN("created_at") AS "first_order", MAX("created_at") AS "last_order" FROM "orders" WHERE "status" = $1 GROUP BY "user_id" HAVING COUNT(*) > $2 OR SUM("total") >= $3;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 3 || params[0] != "paid" || params[1] != 5 || params[2] != 1000.0 {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableSelectHavingMysql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("orders").
+		GroupBy(GroupBy{Column: "user_id"}).
+		Having(Where{Column: "orders.total", Aggregate: AGGREGATE_MAX, Operator: "<", TypedValue: 50}).
+		SelectColumns(Max("orders.total", "max_total")).
+		Select([]string{"user_id"})
+
+	expected := "SELECT `user_id`, MAX(`orders`.`total`) AS `max_total` FROM `orders` GROUP BY `user_id` HAVING MAX(`orders`.`total`) < 50;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectAggregateUnknownPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Unknown aggregate function MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_SQLITE).
+		Table("orders").
+		SelectColumns(SelectColumn{Column: "id", Aggregate: "DROP TABLE users; --"}).
+		Select([]string{})
+}
+
+func TestBuilderTableSelectColumnsNotMutated(t *testing.T) {
+	columns := []string{"id", "first_name AS name"}
+
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Select(columns)
+
+	expected := "SELECT `id`, `first_name` AS `name` FROM `users`;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	if columns[0] != "id" || columns[1] != "first_name AS name" {
+		t.Fatal("Columns MUST NOT be modified but found:", columns)
+	}
+}
+
+func TestBuilderTableSelectDistinctColumnsPostgres(t *testing.T) {
+	sql := NewBuilder(DIALECT_POSTGRES).
+		Table("users").
+		Join(Join{Table: "orders", Alias: "o", On: []JoinOn{{Column: "users.id", OtherColumn: "o.user_id"}}}).
+		Distinct().
+		SelectColumns(
+			SelectColumn{Table: "o", Column: "*"},
+			SelectColumn{Table: "users", Column: "email", Alias: "user_email"},
+			SelectColumn{Raw: `COALESCE("users"."name", '')`, Alias: "user_name"},
+		).
+		Select([]string{"users.id as user_id"})
+
+	expected := `SELECT DISTINCT "users"."id" AS "user_id", "o".*, "users"."email" AS "user_email", COALESCE("users"."name", '') AS "user_name" FROM "users" JOIN "orders" AS "o" ON "users"."id" = "o"."user_id";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableCreateMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Column("id", "integer", map[string]string{
+			"primary": "yes",
+			"auto":    "yes",
+		}).
+		Column("name", "string", map[string]string{}).
+		Column("bio", "text", map[string]string{"nullable": "yes"}).
+		Column("image", "blob", map[string]string{}).
+		Column("price", "decimal", map[string]string{}).
+		Column("created_at", "datetime", map[string]string{}).
+		Create()
+
+	expected := `CREATE TABLE [users]([id] BIGINT IDENTITY(1,1) PRIMARY KEY NOT NULL, [name] NVARCHAR(255) NOT NULL, [bio] NVARCHAR(MAX), [image] VARBINARY(MAX) NOT NULL, [price] DECIMAL(10,2) NOT NULL, [created_at] DATETIME2 NOT NULL);`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableCreateIfNotExistsMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Column("id", "string", map[string]string{"primary": "yes", "length": "40"}).
+		CreateIfNotExists()
+
+	expected := `IF OBJECT_ID(N'users', N'U') IS NULL CREATE TABLE [users]([id] NVARCHAR(40) PRIMARY KEY NOT NULL);`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Where(Where{Column: "name", Operator: "=", Value: "O'Brien"}).
+		Limit(10).
+		Select([]string{"id", "name"})
+
+	expected := `SELECT TOP 10 [id], [name] FROM [users] WHERE [name] = N'O''Brien';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectOffsetMssql(t *testing.T) {
+	builder := NewBuilder(DIALECT_MSSQL).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "status", Operator: "=", Value: "active"}).
+		Distinct().
+		Limit(10).
+		Offset(20)
+
+	sql := builder.Select([]string{"name"})
+
+	expected := `SELECT DISTINCT [name] FROM [users] WHERE [status] = @p1 ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = builder.OrderBy("name", "desc").Select([]string{"name"})
+
+	expected = `SELECT DISTINCT [name] FROM [users] WHERE [status] = @p1 ORDER BY [name] DESC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableSelectOffsetWithoutLimit(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).Table("users").Offset(5).Select([]string{})
+
+	expected := `SELECT * FROM "users" LIMIT -1 OFFSET 5;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = NewBuilder(DIALECT_MYSQL).Table("users").Offset(5).Select([]string{})
+
+	expected = "SELECT * FROM `users` LIMIT 18446744073709551615 OFFSET 5;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = NewBuilder(DIALECT_POSTGRES).Table("users").Offset(5).Select([]string{})
+
+	expected = `SELECT * FROM "users" OFFSET 5;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpdateDeleteMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Where(Where{Column: "id", Operator: "=", Value: "1"}).
+		Limit(1).
+		Update(map[string]any{"name": "Tom"})
+
+	expected := `UPDATE TOP (1) [users] SET [name]=N'Tom' WHERE [id] = N'1';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Where(Where{Column: "id", Operator: "=", Value: "1"}).
+		Limit(1).
+		Delete()
+
+	expected = `DELETE TOP (1) FROM [users] WHERE [id] = N'1';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableDeleteOrderByMssqlPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Delete with ORDER BY and a limit on SQL Server MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		OrderBy("id", "ASC").
+		Limit(5).
+		Delete()
+}
+
+func TestBuilderTableUpdateOrderByMssqlPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Update with ORDER BY and a limit on SQL Server MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		OrderBy("id", "ASC").
+		Limit(5).
+		Update(map[string]any{"name": "Tom"})
+}
+
+func TestBuilderTableInsertMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Limit(1).
+		Insert(map[string]any{"name": "Tom"})
+
+	expected := `INSERT INTO [users] ([name]) VALUES (N'Tom');`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableLimitRejected(t *testing.T) {
+	rejected := map[string]func(){
+		"postgres delete": func() {
+			NewBuilder(DIALECT_POSTGRES).Table("users").Limit(1).Delete()
+		},
+		"postgres update": func() {
+			NewBuilder(DIALECT_POSTGRES).Table("users").Limit(1).Update(map[string]any{"name": "Tom"})
+		},
+		"mysql offset": func() {
+			NewBuilder(DIALECT_MYSQL).Table("users").Limit(1).Offset(1).Delete()
+		},
+		"mssql offset": func() {
+			NewBuilder(DIALECT_MSSQL).Table("users").Limit(1).Offset(1).Update(map[string]any{"name": "Tom"})
+		},
+	}
+
+	for name, fn := range rejected {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("Limit for " + name + " MUST panic")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestBuilderTableUpsertMysql(t *testing.T) {
+	values := map[string]any{
+		"id":         "1",
+		"first_name": "Tom",
+		"last_name":  "Jones",
+	}
+
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Upsert(values, OnConflict{Columns: []string{"id"}})
+
+	expected := "INSERT INTO `users` (`first_name`, `id`, `last_name`) VALUES (\"Tom\", \"1\", \"Jones\") ON DUPLICATE KEY UPDATE `first_name`=VALUES(`first_name`), `last_name`=VALUES(`last_name`);"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Upsert(values, OnConflict{DoNothing: true})
+
+	expected = "INSERT INTO `users` (`first_name`, `id`, `last_name`) VALUES (\"Tom\", \"1\", \"Jones\") ON DUPLICATE KEY UPDATE `first_name`=`first_name`;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpsertWithoutColumnsPanics(t *testing.T) {
+	defer func() {
+		// Not an index out of range on the missing first column
+		if r := recover(); r != "In method Upsert() no columns specified to insert!" {
+			t.Fatal("Upsert without columns MUST panic but got: ", r)
+		}
+	}()
+
+	NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Upsert(map[string]any{}, OnConflict{DoNothing: true})
+}
+
+func TestBuilderTableUpsertMysqlNothingToUpdate(t *testing.T) {
+	// All the columns are conflict columns, the conflicts are skipped
+	// without INSERT IGNORE, which would hide the other errors too
+	sql := NewBuilder(DIALECT_MYSQL).
+		WithParams().
+		Table("users_roles").
+		Upsert(map[string]any{"user_id": 1, "role_id": 2}, OnConflict{Columns: []string{"user_id", "role_id"}})
+
+	expected := "INSERT INTO `users_roles` (`role_id`, `user_id`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `role_id`=`role_id`;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpsertPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users")
+
+	sql := builder.Upsert(map[string]any{
+		"id":         "1",
+		"first_name": "Tom",
+		"last_name":  "Jones",
+	}, OnConflict{Columns: []string{"id"}, UpdateColumns: []string{"last_name"}})
+
+	expected := `INSERT INTO "users" ("first_name", "id", "last_name") VALUES ($1, $2, $3) ON CONFLICT ("id") DO UPDATE SET "last_name"=excluded."last_name";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	if len(builder.Params()) != 3 {
+		t.Fatal("Unexpected params:", builder.Params())
+	}
+}
+
+func TestBuilderTableUpsertSqlite(t *testing.T) {
+	sql := NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Upsert(map[string]any{"id": "1", "name": "Tom"}, OnConflict{DoNothing: true})
+
+	expected := `INSERT INTO "users" ("id", "name") VALUES ('1', 'Tom') ON CONFLICT DO NOTHING;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	// Nothing to update when only the conflict columns are inserted
+	sql = NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		Upsert(map[string]any{"id": "1"}, OnConflict{Columns: []string{"id"}})
+
+	expected = `INSERT INTO "users" ("id") VALUES ('1') ON CONFLICT ("id") DO NOTHING;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpsertMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		WithParams().
+		Table("users").
+		Upsert(map[string]any{"id": "1", "name": "Tom"}, OnConflict{Columns: []string{"id"}})
+
+	expected := `MERGE INTO [users] AS [target] USING (VALUES (@p1, @p2)) AS [source] ([id], [name]) ON [target].[id] = [source].[id] WHEN MATCHED THEN UPDATE SET [name] = [source].[name] WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES ([source].[id], [source].[name]);`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableUpsertWithoutConflictColumnsPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Upsert without conflict columns on Postgres MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_POSTGRES).
+		Table("users").
+		Upsert(map[string]any{"id": "1", "name": "Tom"}, OnConflict{})
+}
+
+func TestBuilderTableInsertManyMysql(t *testing.T) {
+	sqls := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		InsertMany([]map[string]any{
+			{"first_name": "Tom", "last_name": "Jones"},
+			{"first_name": "Sam", "last_name": "Smith"},
+		})
+
+	expected := "INSERT INTO `users` (`first_name`, `last_name`) VALUES (\"Tom\", \"Jones\"), (\"Sam\", \"Smith\");"
+	if len(sqls) != 1 || sqls[0] != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sqls)
+	}
+}
+
+func TestBuilderTableInsertManyParamsChunksPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		ParamsLimit(5).
+		Table("users")
+
+	sqls := builder.InsertMany([]map[string]any{
+		{"first_name": "Tom", "last_name": "Jones"},
+		{"first_name": "Sam", "last_name": "Smith"},
+		{"first_name": "Ann", "last_name": "Brown"},
+	})
+
+	if len(sqls) != 2 {
+		t.Fatal("Expected 2 statements but found:", sqls)
+	}
+
+	expected := `INSERT INTO "users" ("first_name", "last_name") VALUES ($1, $2), ($3, $4);`
+	if sqls[0] != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sqls[0])
+	}
+
+	expected = `INSERT INTO "users" ("first_name", "last_name") VALUES ($1, $2);`
+	if sqls[1] != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sqls[1])
+	}
+
+	params := builder.BatchParams()
+	if len(params) != 2 || len(params[0]) != 4 || len(params[1]) != 2 || params[0][2] != "Sam" || params[1][1] != "Brown" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableInsertManySqliteLimit(t *testing.T) {
+	rows := []map[string]any{}
+	for i := 0; i < 1000; i++ {
+		rows = append(rows, map[string]any{"a": "1", "b": "2", "c": "3"})
+	}
+
+	builder := NewBuilder(DIALECT_SQLITE).WithParams().Table("numbers")
+	sqls := builder.InsertMany(rows)
+
+	// 999 placeholders allow 333 rows of 3 columns
+	if len(sqls) != 4 || len(builder.BatchParams()[0]) != 999 || len(builder.BatchParams()[3]) != 3 {
+		t.Fatal("Expected 4 statements but found:", len(sqls))
+	}
+
+	// Without placeholders there is no limit
+	sqls = NewBuilder(DIALECT_SQLITE).Table("numbers").InsertMany(rows)
+	if len(sqls) != 1 {
+		t.Fatal("Expected 1 statement but found:", len(sqls))
+	}
+
+	// SQL Server allows 1000 rows per VALUES
+	sqls = NewBuilder(DIALECT_MSSQL).Table("numbers").InsertMany(append(rows, rows[0]))
+	if len(sqls) != 2 {
+		t.Fatal("Expected 2 statements but found:", len(sqls))
+	}
+}
+
+func TestBuilderTableInsertManyDifferentColumnsPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("InsertMany with different columns MUST panic")
+		}
+	}()
+
+	NewBuilder(DIALECT_SQLITE).
+		Table("users").
+		InsertMany([]map[string]any{
+			{"first_name": "Tom", "last_name": "Jones"},
+			{"first_name": "Sam", "email": "sam@test.com"},
+		})
+}
+
+func TestBuilderTableReturningPostgres(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		Table("users").
+		Where(Where{Column: "id", Operator: "=", Value: "1"}).
+		Returning("id", "updated_at")
+
+	sql := builder.Insert(map[string]any{"first_name": "Tom"})
+
+	expected := `INSERT INTO "users" ("first_name") VALUES ('Tom') RETURNING "id", "updated_at";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = builder.Update(map[string]any{"first_name": "Tom"})
+
+	expected = `UPDATE "users" SET "first_name"='Tom' WHERE "id" = '1' RETURNING "id", "updated_at";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = builder.Delete()
+
+	expected = `DELETE FROM "users" WHERE "id" = '1' RETURNING "id", "updated_at";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableReturningMssql(t *testing.T) {
+	builder := NewBuilder(DIALECT_MSSQL).
+		Table("users").
+		Where(Where{Column: "id", Operator: "=", Value: "1"}).
+		Returning("id")
+
+	sql := builder.Insert(map[string]any{"first_name": "Tom"})
+
+	expected := `INSERT INTO [users] ([first_name]) OUTPUT INSERTED.[id] VALUES (N'Tom');`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = builder.Update(map[string]any{"first_name": "Tom"})
+
+	expected = `UPDATE [users] SET [first_name]=N'Tom' OUTPUT INSERTED.[id] WHERE [id] = N'1';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	sql = builder.Delete()
+
+	expected = `DELETE FROM [users] OUTPUT DELETED.[id] WHERE [id] = N'1';`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableReturningMysqlIgnored(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users").
+		Returning("id").
+		Insert(map[string]any{"first_name": "Tom"})
+
+	expected := "INSERT INTO `users` (`first_name`) VALUES (\"Tom\");"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableInsertFromSelectPostgres(t *testing.T) {
+	oldOrders := NewBuilder(DIALECT_POSTGRES).
+		Table("orders").
+		Where(Where{Column: "created_at", Operator: "<", Value: "2020-01-01"}).
+		SelectColumns(SelectColumn{Column: "id"}, SelectColumn{Column: "total"})
+
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("orders_archive")
+
+	sql := builder.InsertFromSelect([]string{"order_id", "total"}, oldOrders)
+
+	expected := `INSERT INTO "orders_archive" ("order_id", "total") SELECT "id", "total" FROM "orders" WHERE "created_at" < $1;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 1 || params[0] != "2020-01-01" {
+		t.Fatal("Unexpected params:", params)
+	}
+}
+
+func TestBuilderTableInsertFromSelectMysql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MYSQL).
+		Table("users_copy").
+		InsertFromSelect([]string{}, NewBuilder(DIALECT_MYSQL).Table("users"))
+
+	expected := "INSERT INTO `users_copy` SELECT * FROM `users`;"
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableCreateTableAsSqlite(t *testing.T) {
+	totals := NewBuilder(DIALECT_SQLITE).
+		Table("orders").
+		GroupBy(GroupBy{Column: "user_id"}).
+		SelectColumns(SelectColumn{Column: "user_id"}, Sum("total", "total"))
+
+	sql := NewBuilder(DIALECT_SQLITE).
+		Table("user_totals").
+		CreateTableAs(totals)
+
+	expected := `CREATE TABLE "user_totals" AS SELECT "user_id", SUM("total") AS "total" FROM "orders" GROUP BY "user_id";`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableCreateTableAsMssql(t *testing.T) {
+	sql := NewBuilder(DIALECT_MSSQL).
+		Table("users_copy").
+		CreateTableAs(NewBuilder(DIALECT_MSSQL).Table("users"))
+
+	expected := `SELECT * INTO [users_copy] FROM (SELECT * FROM [users]) AS [source];`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+}
+
+func TestBuilderTableInsertTypedValues(t *testing.T) {
+	createdAt := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	values := map[string]any{
+		"active":     true,
+		"avatar":     []byte{0xde, 0xad},
+		"created_at": createdAt,
+		"deleted_at": nil,
+		"id":         int64(7),
+		"name":       "Tom",
+		"price":      19.5,
+		"score":      sql.NullInt64{Int64: 3, Valid: true},
+	}
+
+	expected := map[string]string{
+		DIALECT_MYSQL:    "INSERT INTO `users` (`active`, `avatar`, `created_at`, `deleted_at`, `id`, `name`, `price`, `score`) VALUES (TRUE, X'dead', \"2023-04-05 06:07:08\", NULL, 7, \"Tom\", 19.5, 3);",
+		DIALECT_POSTGRES: `INSERT INTO "users" ("active", "avatar", "created_at", "deleted_at", "id", "name", "price", "score") VALUES (TRUE, '\xdead', '2023-04-05 06:07:08+00:00', NULL, 7, 'Tom', 19.5, 3);`,
+		DIALECT_SQLITE:   `INSERT INTO "users" ("active", "avatar", "created_at", "deleted_at", "id", "name", "price", "score") VALUES (1, X'dead', '2023-04-05 06:07:08+00:00', NULL, 7, 'Tom', 19.5, 3);`,
+		DIALECT_MSSQL:    `INSERT INTO [users] ([active], [avatar], [created_at], [deleted_at], [id], [name], [price], [score]) VALUES (1, 0xdead, N'2023-04-05 06:07:08', NULL, 7, N'Tom', 19.5, 3);`,
+	}
+
+	for dialect, expectedSql := range expected {
+		sql := NewBuilder(dialect).Table("users").Insert(values)
+		if sql != expectedSql {
+			t.Fatal("Expected:\n", expectedSql, "\nbut found:\n", sql)
+		}
+	}
+}
+
+func TestBuilderTableUpdateTypedValuesParams(t *testing.T) {
+	builder := NewBuilder(DIALECT_POSTGRES).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "id", Operator: "=", Value: "1"})
+
+	sql := builder.Update(map[string]any{
+		"active":     false,
+		"deleted_at": nil,
+	})
+
+	expected := `UPDATE "users" SET "active"=$1, "deleted_at"=$2 WHERE "id" = $3;`
+	if sql != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", sql)
+	}
+
+	params := builder.Params()
+	if len(params) != 3 || params[0] != false || params[1] != nil {
+		t.Fatal("Unexpected params:", params)
+	}
+}
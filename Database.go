@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/georgysavva/scany/sqlscan"
@@ -12,66 +16,33 @@ import (
 	"github.com/gouniverse/uid"
 )
 
-type Database struct {
-	db             *sql.DB
-	tx             *sql.Tx
-	databaseType   string
-	sqlLogEnabled  bool
-	sqlLog         map[string]string
-	sqlDurationLog map[string]time.Duration
-	debug          bool
-}
-
-func (d *Database) SqlLog() []map[string]string {
-	log := []map[string]string{}
-	for key, value := range d.sqlLog {
-		sqlDuration := d.sqlDurationLog[key]
-		log = append(log, map[string]string{
-			"sql":  value,
-			"time": sqlDuration.String(),
-		})
-	}
-	return log
-}
-
-func (d *Database) SqlLogEmpty() {
-	d.sqlLog = map[string]string{}
-	d.sqlDurationLog = map[string]time.Duration{}
-}
-
-func (d *Database) SqlLogLen() int {
-	return len(d.sqlLog)
-}
-
-func (d *Database) SqlLogEnable(enable bool) {
-	d.sqlLogEnabled = enable
-}
-
-func (d *Database) SqlLogShrink(leaveLast int) {
-	if len(d.sqlLog) <= leaveLast {
-		return
-	}
-
-	keys := []string{}
+// ErrNotFound is returned by the single row helpers when the query returns
+// no rows. It wraps sql.ErrNoRows, so errors.Is matches both
+var ErrNotFound = fmt.Errorf("no row found: %w", sql.ErrNoRows)
 
-	for key, _ := range d.sqlLog {
-		keys = append(keys, key)
-	}
-
-	tempSqlLog := map[string]string{}
-	tempSqlDurationLog := map[string]time.Duration{}
-	lastKeys := keys[leaveLast:]
-	for _, key := range lastKeys {
-		tempSqlLog[key] = d.sqlLog[key]
-		tempSqlDurationLog[key] = d.sqlDurationLog[key]
-	}
-
-	d.sqlLog = tempSqlLog
-	d.sqlDurationLog = tempSqlDurationLog
+// Database wraps a DB, and runs the statements within the transaction of
+// the Database when it is a transaction handle (see BeginTx). It is safe for
+// concurrent use, unless bound to a transaction with BeginTransaction
+type Database struct {
+	db           *sql.DB
+	tx           *sql.Tx
+	txDepth      int
+	txID         string
+	handleDepth  int
+	databaseType string
+	logState     *sqlLogState
+	logOnce      sync.Once
+	retryPolicy  *RetryPolicy
 }
 
-func (d *Database) DebugEnable(debug bool) {
-	d.debug = debug
+// logs returns the log state, created on first use
+func (d *Database) logs() *sqlLogState {
+	d.logOnce.Do(func() {
+		if d.logState == nil {
+			d.logState = newSqlLogState()
+		}
+	})
+	return d.logState
 }
 
 func (d *Database) Type() string {
@@ -92,118 +63,543 @@ func (d *Database) DB() *sql.DB {
 }
 
 func (d *Database) BeginTransaction() (err error) {
-	if d.tx != nil {
-		return errors.New("transaction already in progress")
-	}
+	return d.BeginTransactionWithContext(context.Background(), nil)
+}
 
-	tx, err := d.db.Begin()
+// BeginTransactionWithContext binds the database to a new transaction, or
+// to a nested one with a savepoint when a transaction is already in progress.
+// The options apply to the outermost transaction only. A bound database must
+// not be shared by goroutines, use BeginTx for that
+func (d *Database) BeginTransactionWithContext(ctx context.Context, opts *sql.TxOptions) (err error) {
+	txDatabase, err := d.BeginTx(ctx, opts)
 	if err != nil {
-		return errors.New("failed to begin transaction: " + err.Error())
+		return err
 	}
-	d.tx = tx
 
-	return err
+	d.tx = txDatabase.tx
+	d.txID = txDatabase.txID
+	d.txDepth = txDatabase.txDepth
+
+	return nil
 }
 
-func (d *Database) BeginTransactionWithContext(ctx context.Context, opts *sql.TxOptions) (err error) {
+// BeginTx begins a transaction and returns its handle, a Database running the
+// statements within the transaction, to commit or roll back once done. The
+// database itself is not bound to the transaction, so it can be shared by
+// goroutines each having their own transactions. Called on a handle, it
+// begins a nested transaction with a savepoint
+func (d *Database) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Database, error) {
 	if d.tx != nil {
-		return errors.New("transaction already in progress")
+		_, err := d.ExecContext(ctx, d.savepointSql("begin", d.txDepth))
+		if err != nil {
+			return nil, errors.New("failed to begin nested transaction: " + err.Error())
+		}
+
+		return d.transactionDatabase(d.tx, d.txID, d.txDepth+1), nil
 	}
 
 	tx, err := d.db.BeginTx(ctx, opts)
-
 	if err != nil {
-		return errors.New("failed to begin transaction: " + err.Error())
+		return nil, errors.New("failed to begin transaction: " + err.Error())
 	}
 
-	d.tx = tx
+	return d.transactionDatabase(tx, uid.HumanUid(), 1), nil
+}
 
-	return nil
+// TransactionDepth returns the number of transactions in progress, 0 when
+// there is none, 1 for a transaction, and more for nested transactions
+func (d *Database) TransactionDepth() int {
+	return d.txDepth
 }
 
 func (d *Database) ExecInTransaction(fn func(d *Database) error) (err error) {
-	err = d.BeginTransaction()
+	return d.ExecInTransactionContext(context.Background(), fn)
+}
+
+// ExecInTransactionContext runs fn in a transaction begun with the context,
+// committed when fn succeeds and rolled back when it fails or panics (the
+// panic is then raised again). With a retry policy the transaction is run
+// again when it fails with a transient error. A nested transaction is not
+// retried alone, the outermost one is
+func (d *Database) ExecInTransactionContext(ctx context.Context, fn func(d *Database) error) (err error) {
+	if d.tx != nil || d.retryPolicy == nil {
+		return d.execInTransaction(ctx, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = d.execInTransaction(ctx, fn)
+		if err == nil || attempt >= d.retryPolicy.maxAttempts() || !d.retryPolicy.isRetryable(err) {
+			return err
+		}
+
+		logged := d.logEvent(ctx, LOG_EVENT_TRANSACTION_RETRY, "sqldb retrying transaction", slog.Int("attempt", attempt), slog.String("error", err.Error()))
+		if !logged && d.logs().isDebug() {
+			log.Println("sqldb retrying transaction after error: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(d.retryPolicy.backoff(attempt)):
+		}
+	}
+}
+
+func (d *Database) execInTransaction(ctx context.Context, fn func(d *Database) error) (err error) {
+	txDatabase, err := d.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
+		if r := recover(); r != nil {
+			if err := txDatabase.RollbackTransaction(); err != nil {
+				d.logRollbackError(ctx, err)
+			}
+			panic(r)
+		}
+
 		if err != nil {
-			err := d.RollbackTransaction()
+			err := txDatabase.RollbackTransaction()
 			if err != nil {
-				log.Println("sqldb rollback error: " + err.Error())
+				d.logRollbackError(ctx, err)
 			}
 		}
 	}()
 
-	err = fn(&Database{db: d.db, tx: d.tx})
+	err = fn(txDatabase)
 
 	if err == nil {
-		err = d.CommitTransaction()
+		err = txDatabase.CommitTransaction()
 	}
 
 	return
 }
 
-func (d *Database) Exec(sqlStr string, args ...any) (sql.Result, error) {
-	if d.sqlLogEnabled {
-		if d.sqlLog == nil {
-			d.sqlLog = map[string]string{}
-			d.sqlDurationLog = map[string]time.Duration{}
+// logRollbackError logs the error to the logger, or the standard logger
+func (d *Database) logRollbackError(ctx context.Context, err error) {
+	if !d.logEvent(ctx, LOG_EVENT_ROLLBACK_ERROR, "sqldb rollback error", slog.String("error", err.Error())) {
+		log.Println("sqldb rollback error: " + err.Error())
+	}
+}
+
+// transactionDatabase returns the handle of the transaction at the depth,
+// sharing the configuration and the SQL log of the database. The handle
+// keeps the depth it was begun at, it is finished once the transaction at
+// that depth is committed or rolled back
+func (d *Database) transactionDatabase(tx *sql.Tx, txID string, txDepth int) *Database {
+	return &Database{
+		db:           d.db,
+		tx:           tx,
+		txDepth:      txDepth,
+		txID:         txID,
+		databaseType: d.databaseType,
+		handleDepth:  txDepth,
+		logState:     d.logs(),
+		retryPolicy:  d.retryPolicy,
+	}
+}
+
+// TransactionRetryPolicy sets the policy retrying the transactions of
+// ExecInTransaction failing with a transient error
+func (d *Database) TransactionRetryPolicy(policy RetryPolicy) {
+	d.retryPolicy = &policy
+}
+
+// logSql prints the statement when debugging. The returned function adds
+// it to the SQL log, logs it to the logger, and reports it when slow, call
+// it with the outcome when done
+func (d *Database) logSql(ctx context.Context, sqlStr string, args []any) func(rowsAffected int64, err error) {
+	logs := d.logs()
+
+	logs.mutex.Lock()
+	debug, enabled, slowThreshold, logger := logs.debug, logs.enabled, logs.slowThreshold, logs.logger
+	logs.mutex.Unlock()
+
+	if debug && logger == nil {
+		log.Println(sqlStr)
+	}
+
+	if !enabled && slowThreshold <= 0 && logger == nil {
+		return func(int64, error) {}
+	}
+
+	start := time.Now()
+	return func(rowsAffected int64, err error) {
+		entry := SqlLogEntry{
+			SQL:          sqlStr,
+			Args:         append([]any{}, args...),
+			Start:        start,
+			Duration:     time.Since(start),
+			RowsAffected: rowsAffected,
+			Err:          err,
+			TxID:         d.txID,
 		}
 
-		sqlID := uid.HumanUid()
+		logs.add(entry)
 
-		d.sqlLog[sqlID] = sqlStr
+		if logger != nil {
+			d.logEntry(ctx, entry)
+		}
 
-		start := time.Now()
-		defer func() {
-			d.sqlDurationLog[sqlID] = time.Since(start)
-		}()
+		if slowThreshold > 0 && entry.Duration >= slowThreshold {
+			d.reportSlowQuery(entry)
+		}
 	}
+}
 
-	if d.debug {
-		log.Println(sqlStr)
+// rowsAffected returns the rows affected of the result, -1 when unknown
+func rowsAffected(result sql.Result, err error) int64 {
+	if err != nil || result == nil {
+		return -1
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return rowsAffected
+}
+
+func (d *Database) Exec(sqlStr string, args ...any) (sql.Result, error) {
+	return d.ExecContext(context.Background(), sqlStr, args...)
+}
+
+// ExecContext executes the statement within the transaction in progress,
+// or on the database
+func (d *Database) ExecContext(ctx context.Context, sqlStr string, args ...any) (result sql.Result, err error) {
+	statement, err := d.runHooks(ctx, sqlStr, args)
+	if err != nil {
+		return nil, err
 	}
+	ctx, sqlStr, args = statement.ctx, statement.sqlStr, statement.args
+
+	logDone := d.logSql(ctx, sqlStr, args)
+	defer func() {
+		logDone(rowsAffected(result, err), err)
+		statement.after(result, err)
+	}()
 
 	if d.tx != nil {
-		return d.tx.Exec(sqlStr, args...)
+		return d.tx.ExecContext(ctx, sqlStr, args...)
 	}
-	return d.db.Exec(sqlStr, args...)
+	return d.db.ExecContext(ctx, sqlStr, args...)
 }
 
 func (d *Database) Query(sqlStr string, args ...any) (*sql.Rows, error) {
-	if d.sqlLogEnabled {
-		if d.sqlLog == nil {
-			d.sqlLog = map[string]string{}
-			d.sqlDurationLog = map[string]time.Duration{}
-		}
+	return d.QueryContext(context.Background(), sqlStr, args...)
+}
 
-		sqlID := uid.HumanUid()
+// QueryContext executes the query within the transaction in progress,
+// or on the database
+func (d *Database) QueryContext(ctx context.Context, sqlStr string, args ...any) (rows *sql.Rows, err error) {
+	statement, err := d.runHooks(ctx, sqlStr, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, sqlStr, args = statement.ctx, statement.sqlStr, statement.args
 
-		d.sqlLog[sqlID] = sqlStr
+	logDone := d.logSql(ctx, sqlStr, args)
+	defer func() {
+		logDone(-1, err)
+		statement.after(nil, err)
+	}()
 
-		start := time.Now()
-		defer func() {
-			d.sqlDurationLog[sqlID] = time.Since(start)
-		}()
+	if d.tx != nil {
+		return d.tx.QueryContext(ctx, sqlStr, args...)
 	}
+	return d.db.QueryContext(ctx, sqlStr, args...)
+}
 
-	if d.debug {
-		log.Println(sqlStr)
+// QueryRow executes the query, expected to return at most one row, within
+// the transaction in progress, or on the database
+func (d *Database) QueryRow(sqlStr string, args ...any) *sql.Row {
+	return d.QueryRowContext(context.Background(), sqlStr, args...)
+}
+
+// QueryRowContext executes the query, expected to return at most one row,
+// within the transaction in progress, or on the database. When a hook aborts
+// the query, the row fails with the error of the hook
+func (d *Database) QueryRowContext(ctx context.Context, sqlStr string, args ...any) (row *sql.Row) {
+	statement, err := d.runHooks(ctx, sqlStr, args)
+	if err != nil {
+		// A row can not be made with an error, it is queried with a context
+		// done with the error instead, failing before reaching the database
+		return d.db.QueryRowContext(abortedContext{Context: ctx, err: err}, sqlStr, args...)
 	}
+	ctx, sqlStr, args = statement.ctx, statement.sqlStr, statement.args
+
+	logDone := d.logSql(ctx, sqlStr, args)
+	defer func() {
+		logDone(-1, row.Err())
+		statement.after(nil, row.Err())
+	}()
 
 	if d.tx != nil {
-		return d.tx.Query(sqlStr, args...)
+		return d.tx.QueryRowContext(ctx, sqlStr, args...)
+	}
+	return d.db.QueryRowContext(ctx, sqlStr, args...)
+}
+
+// abortedContext is a done context failing with the error aborting the
+// statement
+type abortedContext struct {
+	context.Context
+	err error
+}
+
+func (c abortedContext) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (c abortedContext) Err() error {
+	return c.err
+}
+
+// ExecReturning executes a statement having a RETURNING (or OUTPUT) clause,
+// and returns the rows it returned
+func (d *Database) ExecReturning(sqlStr string, args ...any) ([]map[string]any, error) {
+	return d.ExecReturningContext(context.Background(), sqlStr, args...)
+}
+
+// ExecReturningContext is ExecReturning with a context
+func (d *Database) ExecReturningContext(ctx context.Context, sqlStr string, args ...any) ([]map[string]any, error) {
+	rows, err := d.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return []map[string]any{}, err
+	}
+
+	listMap := []map[string]any{}
+
+	err = sqlscan.ScanAll(&listMap, rows)
+	if err != nil {
+		return []map[string]any{}, err
 	}
-	return d.db.Query(sqlStr, args...)
+
+	return listMap, nil
+}
+
+// InsertReturning inserts the row with the builder, and returns the
+// Returning columns of the inserted row, which are required. MySQL has no
+// RETURNING, there the row is selected back by its LAST_INSERT_ID in the
+// idColumn
+func (d *Database) InsertReturning(builder *Builder, columnValuesMap map[string]any, idColumn string) (map[string]any, error) {
+	return d.InsertReturningContext(context.Background(), builder, columnValuesMap, idColumn)
+}
+
+// InsertReturningContext is InsertReturning with a context
+func (d *Database) InsertReturningContext(ctx context.Context, builder *Builder, columnValuesMap map[string]any, idColumn string) (map[string]any, error) {
+	// Checked first, the row must not be inserted when it can not be returned
+	if len(builder.sqlReturning) < 1 {
+		return nil, errors.New("no Returning columns specified")
+	}
+
+	sqlStr := builder.Insert(columnValuesMap)
+
+	if builder.Dialect == DIALECT_MYSQL {
+		result, err := d.ExecContext(ctx, sqlStr, builder.Params()...)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, errors.New("failed to get last insert id: " + err.Error())
+		}
+
+		selectBuilder := NewBuilder(builder.Dialect).
+			WithParams().
+			Table(builder.sqlTableName).
+			Where(Where{Column: idColumn, Operator: "=", Value: strconv.FormatInt(id, 10)})
+
+		sqlStr = selectBuilder.Select(builder.sqlReturning)
+		builder = selectBuilder
+	}
+
+	rows, err := d.ExecReturningContext(ctx, sqlStr, builder.Params()...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) < 1 {
+		return nil, errors.New("inserted row not returned")
+	}
+
+	return rows[0], nil
+}
+
+// SelectToStructs scans the rows into dest, a pointer to a slice of structs
+// (or of struct pointers). Columns are matched with the db tags of the fields,
+// and with the snake case of the field names when untagged
+func (d *Database) SelectToStructs(dest any, sqlStr string, args ...any) error {
+	return d.SelectToStructsContext(context.Background(), dest, sqlStr, args...)
+}
+
+// SelectToStructsContext is SelectToStructs with a context
+func (d *Database) SelectToStructsContext(ctx context.Context, dest any, sqlStr string, args ...any) error {
+	rows, err := d.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	return sqlscan.ScanAll(dest, rows)
+}
+
+// SelectOne scans the first row into dest, a pointer to a struct,
+// ErrNotFound is returned when there are no rows
+func (d *Database) SelectOne(dest any, sqlStr string, args ...any) error {
+	return d.SelectOneContext(context.Background(), dest, sqlStr, args...)
+}
+
+// SelectOneContext is SelectOne with a context
+func (d *Database) SelectOneContext(ctx context.Context, dest any, sqlStr string, args ...any) error {
+	rows, err := d.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+
+	err = sqlscan.ScanOne(dest, rows)
+	if sqlscan.NotFound(err) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// SelectScalar scans the single column of the first row into dest, a pointer
+// to a value such as *int64, *string or *time.Time, ErrNotFound is returned
+// when there are no rows
+func (d *Database) SelectScalar(dest any, sqlStr string, args ...any) error {
+	return d.SelectScalarContext(context.Background(), dest, sqlStr, args...)
+}
+
+// SelectScalarContext is SelectScalar with a context
+func (d *Database) SelectScalarContext(ctx context.Context, dest any, sqlStr string, args ...any) error {
+	rows, err := d.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return ErrNotFound
+	}
+
+	if err := rows.Scan(dest); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+// SelectString returns the single column of the first row as a string
+func (d *Database) SelectString(sqlStr string, args ...any) (string, error) {
+	return d.SelectStringContext(context.Background(), sqlStr, args...)
+}
+
+// SelectStringContext is SelectString with a context
+func (d *Database) SelectStringContext(ctx context.Context, sqlStr string, args ...any) (string, error) {
+	var value string
+	err := d.SelectScalarContext(ctx, &value, sqlStr, args...)
+	return value, err
 }
 
+// SelectInt64 returns the single column of the first row as an int64,
+// i.e. a count or an id
+func (d *Database) SelectInt64(sqlStr string, args ...any) (int64, error) {
+	return d.SelectInt64Context(context.Background(), sqlStr, args...)
+}
+
+// SelectInt64Context is SelectInt64 with a context
+func (d *Database) SelectInt64Context(ctx context.Context, sqlStr string, args ...any) (int64, error) {
+	var value int64
+	err := d.SelectScalarContext(ctx, &value, sqlStr, args...)
+	return value, err
+}
+
+// SelectBool returns the single column of the first row as a bool,
+// 1 and 0 are converted, as stored by SQLite and SQL Server
+func (d *Database) SelectBool(sqlStr string, args ...any) (bool, error) {
+	return d.SelectBoolContext(context.Background(), sqlStr, args...)
+}
+
+// SelectBoolContext is SelectBool with a context
+func (d *Database) SelectBoolContext(ctx context.Context, sqlStr string, args ...any) (bool, error) {
+	var value bool
+	err := d.SelectScalarContext(ctx, &value, sqlStr, args...)
+	return value, err
+}
+
+// SelectTime returns the single column of the first row as a time. Text
+// values, as returned by SQLite for columns without a date type, are parsed
+func (d *Database) SelectTime(sqlStr string, args ...any) (time.Time, error) {
+	return d.SelectTimeContext(context.Background(), sqlStr, args...)
+}
+
+// SelectTimeContext is SelectTime with a context
+func (d *Database) SelectTimeContext(ctx context.Context, sqlStr string, args ...any) (time.Time, error) {
+	var value any
+	err := d.SelectScalarContext(ctx, &value, sqlStr, args...)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return parseTime(v)
+	case []byte:
+		return parseTime(string(v))
+	}
+
+	return time.Time{}, fmt.Errorf("value %v of type %T is not a time", value, value)
+}
+
+// parseTime parses the text timestamps of the dialects
+func parseTime(value string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("value " + value + " is not a time")
+}
+
+// CommitTransaction commits the transaction, or releases the savepoint of
+// the nested transaction
 func (d *Database) CommitTransaction() (err error) {
 	if d.tx == nil {
 		return errors.New("no transaction in progress")
 	}
 
+	if d.txDepth > 1 {
+		if releaseSql := d.savepointSql("commit", d.txDepth-1); releaseSql != "" {
+			_, err = d.Exec(releaseSql)
+			if err != nil {
+				return errors.New("failed to commit nested transaction: " + err.Error())
+			}
+		}
+
+		d.endNestedTransaction()
+
+		return nil
+	}
+
 	err = d.tx.Commit()
 
 	if err != nil {
@@ -211,15 +607,39 @@ func (d *Database) CommitTransaction() (err error) {
 	}
 
 	d.tx = nil // empty transaction
+	d.txID = ""
+	d.txDepth = 0
 
 	return err
 }
 
+// RollbackTransaction rolls back the transaction, or the changes since the
+// savepoint of the nested transaction
 func (d *Database) RollbackTransaction() (err error) {
 	if d.tx == nil {
 		return errors.New("no transaction in progress")
 	}
 
+	if d.txDepth > 1 {
+		_, err = d.Exec(d.savepointSql("rollback", d.txDepth-1))
+		if err != nil {
+			return errors.New("failed to rollback nested transaction: " + err.Error())
+		}
+
+		// The savepoint outlives the rollback to it, release it so the
+		// transaction is back at the outer level
+		if releaseSql := d.savepointSql("commit", d.txDepth-1); releaseSql != "" {
+			_, err = d.Exec(releaseSql)
+			if err != nil {
+				return errors.New("failed to rollback nested transaction: " + err.Error())
+			}
+		}
+
+		d.endNestedTransaction()
+
+		return nil
+	}
+
 	err = d.tx.Rollback()
 
 	if err != nil {
@@ -227,47 +647,84 @@ func (d *Database) RollbackTransaction() (err error) {
 	}
 
 	d.tx = nil // empty transaction
+	d.txID = ""
+	d.txDepth = 0
 
 	return err
 }
 
-func (d *Database) SelectToMapAny(sqlStr string, args ...any) ([]map[string]any, error) {
-	if d.sqlLogEnabled {
-		if d.sqlLog == nil {
-			d.sqlLog = map[string]string{}
-			d.sqlDurationLog = map[string]time.Duration{}
-		}
+// endNestedTransaction leaves the nested transaction just committed or
+// rolled back. A database bound with BeginTransaction is back at the outer
+// transaction, while the handle of the nested transaction is finished, it
+// must not act on the outer transaction it shares
+func (d *Database) endNestedTransaction() {
+	if d.txDepth > d.handleDepth {
+		d.txDepth--
+		return
+	}
 
-		sqlID := uid.HumanUid()
+	d.tx = nil
+	d.txID = ""
+	d.txDepth = 0
+}
 
-		d.sqlLog[sqlID] = sqlStr
+// savepointSql returns the SQL beginning ("begin"), committing ("commit")
+// or rolling back ("rollback") the savepoint of the nested transaction at
+// the level. SQL Server has no release, committing there is a no-op
+func (d *Database) savepointSql(action string, level int) string {
+	name := "sp_" + strconv.Itoa(level)
+
+	if d.databaseType == DIALECT_MSSQL {
+		switch action {
+		case "begin":
+			return "SAVE TRANSACTION " + name + ";"
+		case "rollback":
+			return "ROLLBACK TRANSACTION " + name + ";"
+		}
+		return ""
+	}
 
-		start := time.Now()
-		defer func() {
-			d.sqlDurationLog[sqlID] = time.Since(start)
-		}()
+	switch action {
+	case "begin":
+		return "SAVEPOINT " + name + ";"
+	case "rollback":
+		return "ROLLBACK TO SAVEPOINT " + name + ";"
 	}
+	return "RELEASE SAVEPOINT " + name + ";"
+}
 
-	if d.debug {
-		log.Println(sqlStr)
+// SelectToMapAny executes the select and returns the rows as maps
+func (d *Database) SelectToMapAny(sqlStr string, args ...any) ([]map[string]any, error) {
+	return d.SelectToMapAnyContext(context.Background(), sqlStr, args...)
+}
+
+// SelectToMapAnyContext executes the select with the arguments, within the
+// transaction when one is in progress, and returns the rows as maps
+func (d *Database) SelectToMapAnyContext(ctx context.Context, sqlStr string, args ...any) ([]map[string]any, error) {
+	rows, err := d.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return []map[string]any{}, err
 	}
 
 	listMap := []map[string]any{}
 
-	err := sqlscan.Select(context.Background(), d.db, &listMap, sqlStr)
+	err = sqlscan.ScanAll(&listMap, rows)
 	if err != nil {
-		if sqlscan.NotFound(err) {
-			return []map[string]any{}, nil
-		}
-
 		return []map[string]any{}, err
 	}
 
 	return listMap, nil
 }
 
+// SelectToMapString executes the select and returns the rows as maps of strings
 func (d *Database) SelectToMapString(sqlStr string, args ...any) ([]map[string]string, error) {
-	listMapAny, err := d.SelectToMapAny(sqlStr, args...)
+	return d.SelectToMapStringContext(context.Background(), sqlStr, args...)
+}
+
+// SelectToMapStringContext executes the select with the arguments, within the
+// transaction when one is in progress, and returns the rows as maps of strings
+func (d *Database) SelectToMapStringContext(ctx context.Context, sqlStr string, args ...any) ([]map[string]string, error) {
+	listMapAny, err := d.SelectToMapAnyContext(ctx, sqlStr, args...)
 
 	if err != nil {
 		return []map[string]string{}, err
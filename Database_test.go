@@ -0,0 +1,735 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDatabase(t *testing.T) *Database {
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	// Every connection has its own in memory database
+	conn.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	db := NewDatabase(conn, DIALECT_SQLITE)
+
+	_, err = db.Exec(`CREATE TABLE "users" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "first_name" TEXT, "status" TEXT DEFAULT 'new')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	return db
+}
+
+func TestDatabaseInsertReturning(t *testing.T) {
+	db := newTestDatabase(t)
+
+	builder := NewBuilder(DIALECT_SQLITE).
+		WithParams().
+		Table("users").
+		Returning("id", "status")
+
+	row, err := db.InsertReturning(builder, map[string]any{"first_name": "Tom"}, "id")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if row["id"] != int64(1) || row["status"] != "new" {
+		t.Fatal("Unexpected row:", row)
+	}
+}
+
+func TestDatabaseInsertReturningWithoutColumns(t *testing.T) {
+	db := newTestDatabase(t)
+
+	builder := NewBuilder(DIALECT_SQLITE).WithParams().Table("users")
+
+	_, err := db.InsertReturning(builder, map[string]any{"first_name": "Tom"}, "id")
+	if err == nil {
+		t.Fatal("Expected an error without Returning columns")
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 0 {
+		t.Fatal("Expected the row not to be inserted but got: ", count, err)
+	}
+}
+
+func TestDatabaseSelectHaving(t *testing.T) {
+	db := newTestDatabase(t)
+
+	for i := 0; i < 9; i++ {
+		firstName := "Tom"
+		if i >= 7 {
+			firstName = "Ann"
+		}
+		_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, firstName)
+		if err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+	}
+
+	// The count is compared with a number, SQLite orders any text after the
+	// numbers, so a string would never be smaller than the count
+	for _, builder := range []*Builder{NewBuilder(DIALECT_SQLITE), NewBuilder(DIALECT_SQLITE).WithParams()} {
+		sqlStr := builder.
+			Table("users").
+			GroupBy(GroupBy{Column: "first_name"}).
+			Having(Where{Column: "*", Aggregate: AGGREGATE_COUNT, Operator: ">", TypedValue: 5}).
+			SelectColumns(Count("*", "total")).
+			Select([]string{"first_name"})
+
+		rows, err := db.SelectToMapString(sqlStr, builder.Params()...)
+		if err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+
+		if len(rows) != 1 || rows[0]["first_name"] != "Tom" || rows[0]["total"] != "7" {
+			t.Fatal("Unexpected rows: ", sqlStr, rows)
+		}
+	}
+}
+
+func TestDatabaseExecReturning(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom'), ('Sam')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	builder := NewBuilder(DIALECT_SQLITE).
+		WithParams().
+		Table("users").
+		Where(Where{Column: "first_name", Operator: "=", Value: "Sam"}).
+		Returning("id", "status")
+
+	rows, err := db.ExecReturning(builder.Update(map[string]any{"status": "active"}), builder.Params()...)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(rows) != 1 || rows[0]["id"] != int64(2) || rows[0]["status"] != "active" {
+		t.Fatal("Unexpected rows:", rows)
+	}
+}
+
+func TestDatabaseInsertTypedValues(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Exec(`CREATE TABLE "files" ("id" INTEGER, "data" BLOB, "active" BOOLEAN, "deleted_at" DATETIME)`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	sqlStr := NewBuilder(DIALECT_SQLITE).
+		Table("files").
+		Insert(map[string]any{
+			"id":         1,
+			"data":       []byte("hello"),
+			"active":     true,
+			"deleted_at": nil,
+		})
+
+	_, err = db.Exec(sqlStr)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	rows, err := db.ExecReturning(`SELECT "data", "active", "deleted_at" FROM "files"`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(rows) != 1 || string(rows[0]["data"].([]byte)) != "hello" || rows[0]["active"] != true || rows[0]["deleted_at"] != nil {
+		t.Fatal("Unexpected rows:", rows)
+	}
+}
+
+type testUserRow struct {
+	ID        int64  `db:"id"`
+	FirstName string `db:"first_name"`
+	Status    string
+}
+
+func TestDatabaseSelectToStructs(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom'), ('Sam')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	users := []testUserRow{}
+	err = db.SelectToStructs(&users, `SELECT "id", "first_name", "status" FROM "users" WHERE "id" > ? ORDER BY "id"`, 0)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(users) != 2 || users[1].ID != 2 || users[1].FirstName != "Sam" || users[1].Status != "new" {
+		t.Fatal("Unexpected users:", users)
+	}
+}
+
+func TestDatabaseSelectOne(t *testing.T) {
+	db := newTestDatabase(t)
+
+	err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+	defer db.RollbackTransaction()
+
+	_, err = db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	user := testUserRow{}
+	err = db.SelectOne(&user, `SELECT "id", "first_name", "status" FROM "users" WHERE "first_name" = ?`, "Tom")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if user.ID != 1 || user.FirstName != "Tom" {
+		t.Fatal("Unexpected user:", user)
+	}
+
+	err = db.SelectOne(&user, `SELECT "id", "first_name", "status" FROM "users" WHERE "first_name" = ?`, "Sam")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("Expected ErrNotFound but got: ", err)
+	}
+}
+
+func TestDatabaseSelectScalar(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom'), ('Sam')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	var count int64
+	err = db.SelectScalar(&count, `SELECT COUNT(*) FROM "users"`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if count != 2 {
+		t.Fatal("Expected 2 but got: ", count)
+	}
+
+	var name string
+	err = db.SelectScalar(&name, `SELECT "first_name" FROM "users" WHERE "id" = ?`, 3)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("Expected ErrNotFound but got: ", err)
+	}
+}
+
+func TestDatabaseSelectToMapAnyArgsInTransaction(t *testing.T) {
+	db := newTestDatabase(t)
+
+	err := db.ExecInTransaction(func(tx *Database) error {
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom'), ('Sam')`)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.SelectToMapAny(`SELECT "first_name" FROM "users" WHERE "first_name" = ?`, "Sam")
+		if err != nil {
+			return err
+		}
+
+		if len(rows) != 1 || rows[0]["first_name"] != "Sam" {
+			t.Fatal("Unexpected rows:", rows)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+}
+
+func TestDatabaseSelectToMapStringContext(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	rows, err := db.SelectToMapStringContext(context.Background(), `SELECT "id", "first_name" FROM "users" WHERE "id" = ?`, 1)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(rows) != 1 || rows[0]["id"] != "1" || rows[0]["first_name"] != "Tom" {
+		t.Fatal("Unexpected rows:", rows)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = db.SelectToMapStringContext(ctx, `SELECT "id" FROM "users"`)
+	if err == nil {
+		t.Fatal("Expected an error for a cancelled context")
+	}
+}
+
+func TestDatabaseContextCancelled(t *testing.T) {
+	db := newTestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := db.ExecContext(ctx, `INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err == nil {
+		t.Fatal("Expected an error for a cancelled context")
+	}
+
+	_, err = db.QueryContext(ctx, `SELECT "id" FROM "users"`)
+	if err == nil {
+		t.Fatal("Expected an error for a cancelled context")
+	}
+
+	var id int64
+	err = db.QueryRowContext(ctx, `SELECT "id" FROM "users"`).Scan(&id)
+	if err == nil {
+		t.Fatal("Expected an error for a cancelled context")
+	}
+
+	err = db.ExecInTransactionContext(ctx, func(tx *Database) error {
+		t.Fatal("The transaction must not begin for a cancelled context")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected an error for a cancelled context")
+	}
+}
+
+func TestDatabaseSqlLogContext(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+
+	_, err := db.ExecContext(context.Background(), `INSERT INTO "users" ("first_name") VALUES (?)`, "Tom")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	_, err = db.SelectToMapAnyContext(context.Background(), `SELECT "id" FROM "users"`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if db.SqlLogLen() != 2 {
+		t.Fatal("Expected 2 logged statements but got: ", db.SqlLogLen())
+	}
+}
+
+func TestDatabaseQueryRow(t *testing.T) {
+	db := newTestDatabase(t)
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	var name string
+	err = db.QueryRow(`SELECT "first_name" FROM "users" WHERE "id" = ?`, 1).Scan(&name)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if name != "Tom" {
+		t.Fatal("Expected Tom but got: ", name)
+	}
+}
+
+func TestDatabaseSelectSingleValues(t *testing.T) {
+	db := newTestDatabase(t)
+
+	err := db.BeginTransaction()
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+	defer db.RollbackTransaction()
+
+	_, err = db.Exec(`INSERT INTO "users" ("first_name", "status") VALUES ('Tom', '2023-04-05 06:07:08')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	name, err := db.SelectString(`SELECT "first_name" FROM "users" WHERE "id" = ?`, 1)
+	if err != nil || name != "Tom" {
+		t.Fatal("Expected Tom but got: ", name, err)
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 1 {
+		t.Fatal("Expected 1 but got: ", count, err)
+	}
+
+	exists, err := db.SelectBool(`SELECT COUNT(*) > 0 FROM "users"`)
+	if err != nil || !exists {
+		t.Fatal("Expected true but got: ", exists, err)
+	}
+
+	createdAt, err := db.SelectTime(`SELECT "status" FROM "users" WHERE "id" = ?`, 1)
+	if err != nil || !createdAt.Equal(time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)) {
+		t.Fatal("Unexpected time: ", createdAt, err)
+	}
+
+	_, err = db.SelectString(`SELECT "first_name" FROM "users" WHERE "id" = ?`, 2)
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Fatal("Expected ErrNotFound but got: ", err)
+	}
+}
+
+func TestDatabaseNestedTransactions(t *testing.T) {
+	db := newTestDatabase(t)
+
+	err := db.ExecInTransaction(func(tx *Database) error {
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+		if err != nil {
+			return err
+		}
+
+		// The failing nested transaction rolls back to its savepoint only
+		err = tx.ExecInTransaction(func(nested *Database) error {
+			if nested.TransactionDepth() != 2 {
+				t.Fatal("Expected depth 2 but got: ", nested.TransactionDepth())
+			}
+
+			_, err := nested.Exec(`INSERT INTO "users" ("first_name") VALUES ('Sam')`)
+			if err != nil {
+				return err
+			}
+
+			return errors.New("nested failure")
+		})
+		if err == nil || err.Error() != "nested failure" {
+			t.Fatal("Expected the nested failure but got: ", err)
+		}
+
+		if tx.TransactionDepth() != 1 {
+			t.Fatal("Expected depth 1 but got: ", tx.TransactionDepth())
+		}
+
+		return tx.ExecInTransaction(func(nested *Database) error {
+			_, err := nested.Exec(`INSERT INTO "users" ("first_name") VALUES ('Ann')`)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if db.TransactionDepth() != 0 {
+		t.Fatal("Expected depth 0 but got: ", db.TransactionDepth())
+	}
+
+	names, err := db.SelectToMapString(`SELECT "first_name" FROM "users" ORDER BY "id"`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(names) != 2 || names[0]["first_name"] != "Tom" || names[1]["first_name"] != "Ann" {
+		t.Fatal("Unexpected rows:", names)
+	}
+}
+
+func TestDatabaseNestedBeginCommitRollback(t *testing.T) {
+	db := newTestDatabase(t)
+
+	for _, step := range []func() error{db.BeginTransaction, db.BeginTransaction, db.BeginTransaction} {
+		if err := step(); err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+	}
+
+	if db.TransactionDepth() != 3 {
+		t.Fatal("Expected depth 3 but got: ", db.TransactionDepth())
+	}
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	for _, step := range []func() error{db.CommitTransaction, db.RollbackTransaction, db.CommitTransaction} {
+		if err := step(); err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 0 {
+		t.Fatal("Expected 0 rows but got: ", count, err)
+	}
+
+	if err := db.CommitTransaction(); err == nil {
+		t.Fatal("Expected an error when no transaction is in progress")
+	}
+}
+
+func TestDatabaseBeginTxNestedFinished(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	outer, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	_, err = outer.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	func() {
+		inner, err := outer.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+		// The deferred rollback after the commit must not roll back the
+		// outer transaction
+		defer inner.RollbackTransaction()
+
+		_, err = inner.Exec(`INSERT INTO "users" ("first_name") VALUES ('Ann')`)
+		if err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+
+		if err := inner.CommitTransaction(); err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+
+		if err := inner.CommitTransaction(); err == nil {
+			t.Fatal("Expected an error when the nested transaction is finished")
+		}
+	}()
+
+	if outer.TransactionDepth() != 1 {
+		t.Fatal("Expected depth 1 but got: ", outer.TransactionDepth())
+	}
+
+	if err := outer.CommitTransaction(); err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 2 {
+		t.Fatal("Expected 2 rows but got: ", count, err)
+	}
+}
+
+func TestDatabaseSavepointSql(t *testing.T) {
+	mysql := &Database{databaseType: DIALECT_MYSQL}
+	if sql := mysql.savepointSql("rollback", 2); sql != "ROLLBACK TO SAVEPOINT sp_2;" {
+		t.Fatal("Unexpected SQL: ", sql)
+	}
+
+	mssql := &Database{databaseType: DIALECT_MSSQL}
+	if sql := mssql.savepointSql("begin", 1); sql != "SAVE TRANSACTION sp_1;" {
+		t.Fatal("Unexpected SQL: ", sql)
+	}
+	if sql := mssql.savepointSql("commit", 1); sql != "" {
+		t.Fatal("Unexpected SQL: ", sql)
+	}
+}
+
+func TestDatabaseExecInTransactionPanic(t *testing.T) {
+	db := newTestDatabase(t)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatal("Expected the panic to be raised again but got: ", r)
+			}
+		}()
+
+		db.ExecInTransaction(func(tx *Database) error {
+			_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+			if err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	if db.TransactionDepth() != 0 {
+		t.Fatal("Expected the transaction to be rolled back, depth is: ", db.TransactionDepth())
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 0 {
+		t.Fatal("Expected 0 rows but got: ", count, err)
+	}
+}
+
+func TestDatabaseExecInTransactionConfig(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+
+	err := db.ExecInTransaction(func(tx *Database) error {
+		if tx.Type() != DIALECT_SQLITE {
+			t.Fatal("Expected the dialect to be propagated but got: ", tx.Type())
+		}
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+		return err
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	// CREATE TABLE is not logged, as the log was enabled after it
+	if db.SqlLogLen() != 1 {
+		t.Fatal("Expected the statement of the transaction to be logged, log length: ", db.SqlLogLen())
+	}
+}
+
+func TestDatabaseExecInTransactionRetry(t *testing.T) {
+	db := newTestDatabase(t)
+	db.TransactionRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	attempts := 0
+	err := db.ExecInTransaction(func(tx *Database) error {
+		attempts++
+
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+		if err != nil {
+			return err
+		}
+
+		if attempts < 3 {
+			return errors.New("database is locked")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if attempts != 3 {
+		t.Fatal("Expected 3 attempts but got: ", attempts)
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 1 {
+		t.Fatal("Expected 1 row but got: ", count, err)
+	}
+
+	attempts = 0
+	err = db.ExecInTransaction(func(tx *Database) error {
+		attempts++
+		return errors.New("not transient")
+	})
+	if err == nil || attempts != 1 {
+		t.Fatal("Expected a single attempt for a permanent error, attempts: ", attempts)
+	}
+}
+
+func TestDatabaseBeginTxIsolated(t *testing.T) {
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	db := NewDatabase(conn, DIALECT_SQLITE)
+
+	_, err = db.Exec(`CREATE TABLE "users" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "first_name" TEXT)`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	_, err = tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	// The database is not bound to the transaction of the handle
+	if db.TransactionDepth() != 0 || tx.TransactionDepth() != 1 {
+		t.Fatal("Unexpected depths: ", db.TransactionDepth(), tx.TransactionDepth())
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 0 {
+		t.Fatal("Expected the uncommitted row to be invisible but got: ", count, err)
+	}
+
+	err = tx.CommitTransaction()
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	count, err = db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 1 {
+		t.Fatal("Expected the committed row but got: ", count, err)
+	}
+}
+
+func TestDatabaseConcurrentTransactions(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 20)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			errs <- db.ExecInTransaction(func(tx *Database) error {
+				_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "user"+strconv.Itoa(i))
+				return err
+			})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			db.DebugEnable(false)
+			db.SqlLog()
+			_, err := db.SelectToMapAny(`SELECT "id" FROM "users"`)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+	}
+
+	count, err := db.SelectInt64(`SELECT COUNT(*) FROM "users"`)
+	if err != nil || count != 10 {
+		t.Fatal("Expected 10 rows but got: ", count, err)
+	}
+}
@@ -0,0 +1,10 @@
+package sql
+
+import "strings"
+
+// EscapeLike escapes the LIKE wildcards (% and _) and the backslash escape
+// character, so the value is matched literally by a LIKE condition
+func EscapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
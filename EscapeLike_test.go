@@ -0,0 +1,12 @@
+package sql
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	escaped := EscapeLike(`100%_off\now`)
+
+	expected := `100\%\_off\\now`
+	if escaped != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", escaped)
+	}
+}
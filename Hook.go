@@ -0,0 +1,109 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Hook intercepts the statements executed by Exec, Query and QueryRow (and
+// so by all the helpers built on them), i.e. for tracing, metrics, query
+// rewriting, auditing or read-only enforcement
+type Hook interface {
+	// Before is called before the statement is executed. It returns the
+	// context, SQL and arguments passed on to the next hook and to the
+	// database, so it can rewrite the statement. An error aborts the
+	// statement, and is returned to the caller
+	Before(ctx context.Context, sqlStr string, args []any) (context.Context, string, []any, error)
+
+	// After is called once the statement is executed, or aborted by a later
+	// hook, with the context, SQL and arguments returned by Before. It is not
+	// called when Before returned an error. The result is nil for queries
+	After(ctx context.Context, sqlStr string, args []any, result sql.Result, err error)
+}
+
+// HookFuncs is a Hook made of functions, either one may be nil
+type HookFuncs struct {
+	BeforeFunc func(ctx context.Context, sqlStr string, args []any) (context.Context, string, []any, error)
+	AfterFunc  func(ctx context.Context, sqlStr string, args []any, result sql.Result, err error)
+}
+
+func (h HookFuncs) Before(ctx context.Context, sqlStr string, args []any) (context.Context, string, []any, error) {
+	if h.BeforeFunc == nil {
+		return ctx, sqlStr, args, nil
+	}
+	return h.BeforeFunc(ctx, sqlStr, args)
+}
+
+func (h HookFuncs) After(ctx context.Context, sqlStr string, args []any, result sql.Result, err error) {
+	if h.AfterFunc != nil {
+		h.AfterFunc(ctx, sqlStr, args, result, err)
+	}
+}
+
+// AddHook appends the hook to the chain, shared with the transactions.
+// Before is called in the order the hooks were added, and After in the
+// reverse order, so the first hook wraps all the others
+func (d *Database) AddHook(hook Hook) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.hooks = append(append([]Hook{}, logs.hooks...), hook)
+}
+
+// hookedStatement is a statement passed through the Before of the hooks
+type hookedStatement struct {
+	ctx    context.Context
+	sqlStr string
+	args   []any
+	after  func(result sql.Result, err error)
+}
+
+// runHooks calls Before of the hooks. The returned statement holds the
+// rewritten context, SQL and arguments, and the function calling After of
+// the hooks whose Before was called. On error After is already called
+func (d *Database) runHooks(ctx context.Context, sqlStr string, args []any) (hookedStatement, error) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	hooks := logs.hooks
+	logs.mutex.Unlock()
+
+	statement := hookedStatement{
+		ctx:    ctx,
+		sqlStr: sqlStr,
+		args:   args,
+		after:  func(sql.Result, error) {},
+	}
+
+	if len(hooks) < 1 {
+		return statement, nil
+	}
+
+	type called struct {
+		hook   Hook
+		ctx    context.Context
+		sqlStr string
+		args   []any
+	}
+	calledHooks := []called{}
+
+	statement.after = func(result sql.Result, err error) {
+		for i := len(calledHooks) - 1; i >= 0; i-- {
+			calledHook := calledHooks[i]
+			calledHook.hook.After(calledHook.ctx, calledHook.sqlStr, calledHook.args, result, err)
+		}
+	}
+
+	for _, hook := range hooks {
+		hookCtx, hookSql, hookArgs, err := hook.Before(statement.ctx, statement.sqlStr, statement.args)
+		if err != nil {
+			statement.after(nil, err)
+			return statement, err
+		}
+
+		statement.ctx, statement.sqlStr, statement.args = hookCtx, hookSql, hookArgs
+		calledHooks = append(calledHooks, called{hook: hook, ctx: hookCtx, sqlStr: hookSql, args: hookArgs})
+	}
+
+	return statement, nil
+}
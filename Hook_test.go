@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testHookKey struct{}
+
+func TestHookChainOrder(t *testing.T) {
+	db := newTestDatabase(t)
+
+	calls := []string{}
+	for _, name := range []string{"first", "second"} {
+		name := name
+		db.AddHook(HookFuncs{
+			BeforeFunc: func(ctx context.Context, sqlStr string, args []any) (context.Context, string, []any, error) {
+				calls = append(calls, "before "+name)
+				return context.WithValue(ctx, testHookKey{}, name), sqlStr, args, nil
+			},
+			AfterFunc: func(ctx context.Context, sqlStr string, args []any, result sql.Result, err error) {
+				calls = append(calls, "after "+name+" "+ctx.Value(testHookKey{}).(string))
+				if result == nil || err != nil {
+					t.Fatal("Expected the result of the statement but got: ", result, err)
+				}
+			},
+		})
+	}
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "Tom")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	expected := "before first, before second, after second second, after first first"
+	if strings.Join(calls, ", ") != expected {
+		t.Fatal("Expected:\n", expected, "\nbut found:\n", strings.Join(calls, ", "))
+	}
+}
+
+func TestHookRewrite(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+
+	// Soft deleted users are never selected
+	db.AddHook(HookFuncs{
+		BeforeFunc: func(ctx context.Context, sqlStr string, args []any) (context.Context, string, []any, error) {
+			return ctx, strings.Replace(sqlStr, `FROM "users"`, `FROM "users" WHERE "status" <> ?`, 1), append(args, "deleted"), nil
+		},
+	})
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name", "status") VALUES ('Tom', 'new'), ('Sam', 'deleted')`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	names, err := db.SelectToMapString(`SELECT "first_name" FROM "users"`)
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(names) != 1 || names[0]["first_name"] != "Tom" {
+		t.Fatal("Unexpected rows:", names)
+	}
+
+	// The rewritten statement is logged
+	entries := db.SqlLog()
+	if entries[1].SQL != `SELECT "first_name" FROM "users" WHERE "status" <> ?` || entries[1].Args[0] != "deleted" {
+		t.Fatal("Unexpected log entry: ", entries[1])
+	}
+}
+
+func TestHookReadOnly(t *testing.T) {
+	db := newTestDatabase(t)
+
+	errReadOnly := errors.New("read-only database")
+	afterErrs := []error{}
+
+	// The audit hook wraps the read-only one, so it sees the aborted statements
+	db.AddHook(HookFuncs{
+		AfterFunc: func(ctx context.Context, sqlStr string, args []any, result sql.Result, err error) {
+			afterErrs = append(afterErrs, err)
+		},
+	})
+	db.AddHook(HookFuncs{
+		BeforeFunc: func(ctx context.Context, sqlStr string, args []any) (context.Context, string, []any, error) {
+			if !strings.HasPrefix(sqlStr, "SELECT") {
+				return ctx, sqlStr, args, errReadOnly
+			}
+			return ctx, sqlStr, args, nil
+		},
+		AfterFunc: func(ctx context.Context, sqlStr string, args []any, result sql.Result, err error) {
+			if err == errReadOnly {
+				t.Fatal("After must not be called for the hook aborting the statement")
+			}
+		},
+	})
+
+	err := db.ExecInTransaction(func(tx *Database) error {
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+		return err
+	})
+	if err != errReadOnly {
+		t.Fatal("Expected the hook error but got: ", err)
+	}
+
+	var count int64
+	err = db.QueryRow(`SELECT COUNT(*) FROM "users"`).Scan(&count)
+	if err != nil || count != 0 {
+		t.Fatal("Expected 0 rows but got: ", count, err)
+	}
+
+	err = db.QueryRow(`DELETE FROM "users" RETURNING "id"`).Scan(&count)
+	if err != errReadOnly {
+		t.Fatal("Expected the hook error but got: ", err)
+	}
+
+	if len(afterErrs) != 3 || afterErrs[0] != errReadOnly || afterErrs[1] != nil || afterErrs[2] != errReadOnly {
+		t.Fatal("Unexpected errors passed to After: ", afterErrs)
+	}
+
+	// Within a transaction as well, the transaction stays usable
+	err = db.ExecInTransaction(func(tx *Database) error {
+		err := tx.QueryRow(`DELETE FROM "users" RETURNING "id"`).Scan(&count)
+		if err != errReadOnly {
+			t.Fatal("Expected the hook error but got: ", err)
+		}
+		return tx.QueryRow(`SELECT COUNT(*) FROM "users"`).Scan(&count)
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+}
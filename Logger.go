@@ -0,0 +1,149 @@
+package sql
+
+import (
+	"context"
+	"log/slog"
+)
+
+// The events logged to the logger of the database, see LogLevel
+const (
+	// LOG_EVENT_QUERY is a statement executed successfully (default level debug)
+	LOG_EVENT_QUERY = "query"
+
+	// LOG_EVENT_QUERY_ERROR is a statement which failed (default level error)
+	LOG_EVENT_QUERY_ERROR = "query_error"
+
+	// LOG_EVENT_SLOW_QUERY is a statement over the slow query threshold
+	// (default level warn)
+	LOG_EVENT_SLOW_QUERY = "slow_query"
+
+	// LOG_EVENT_TRANSACTION_RETRY is a transaction run again by the retry
+	// policy (default level warn)
+	LOG_EVENT_TRANSACTION_RETRY = "transaction_retry"
+
+	// LOG_EVENT_ROLLBACK_ERROR is a transaction which failed to roll back
+	// (default level error)
+	LOG_EVENT_ROLLBACK_ERROR = "rollback_error"
+)
+
+var defaultLogLevels = map[string]slog.Level{
+	LOG_EVENT_QUERY:             slog.LevelDebug,
+	LOG_EVENT_QUERY_ERROR:       slog.LevelError,
+	LOG_EVENT_SLOW_QUERY:        slog.LevelWarn,
+	LOG_EVENT_TRANSACTION_RETRY: slog.LevelWarn,
+	LOG_EVENT_ROLLBACK_ERROR:    slog.LevelError,
+}
+
+// ArgsRedactor returns the arguments of the statement as they are logged
+type ArgsRedactor func(sqlStr string, args []any) []any
+
+// RedactAll logs every argument as REDACTED, the default
+func RedactAll(sqlStr string, args []any) []any {
+	redacted := make([]any, len(args))
+	for i := range args {
+		redacted[i] = "REDACTED"
+	}
+	return redacted
+}
+
+// RedactNone logs the arguments as they are
+func RedactNone(sqlStr string, args []any) []any {
+	return args
+}
+
+// Logger sets the logger receiving structured records of the statements
+// and the transactions, instead of the standard logger used by DebugEnable.
+// The records have the attributes sql, args, duration, rows_affected, tx_id,
+// dialect and error. A nil logger turns it off
+func (d *Database) Logger(logger *slog.Logger) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.logger = logger
+}
+
+// LogLevel sets the level of the records of the event, one of the LOG_EVENT_*
+func (d *Database) LogLevel(event string, level slog.Level) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	if logs.logLevels == nil {
+		logs.logLevels = map[string]slog.Level{}
+	}
+
+	logs.logLevels[event] = level
+}
+
+// LogArgsRedactor sets how the arguments of the statements are logged
+// (default RedactAll)
+func (d *Database) LogArgsRedactor(redactor ArgsRedactor) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.argsRedactor = redactor
+}
+
+// logEvent logs the record of the event to the logger, and returns false
+// when there is no logger
+func (d *Database) logEvent(ctx context.Context, event string, message string, attrs ...slog.Attr) bool {
+	logs := d.logs()
+	logs.mutex.Lock()
+	logger := logs.logger
+	level, hasLevel := logs.logLevels[event]
+	logs.mutex.Unlock()
+
+	if logger == nil {
+		return false
+	}
+
+	if !hasLevel {
+		level = defaultLogLevels[event]
+	}
+
+	attrs = append(attrs, slog.String("dialect", d.databaseType))
+	logger.LogAttrs(ctx, level, message, attrs...)
+
+	return true
+}
+
+// logEntry logs the statement as a query or a query error
+func (d *Database) logEntry(ctx context.Context, entry SqlLogEntry) {
+	event := LOG_EVENT_QUERY
+	if entry.Err != nil {
+		event = LOG_EVENT_QUERY_ERROR
+	}
+
+	d.logEvent(ctx, event, "sqldb "+event, d.entryAttrs(entry)...)
+}
+
+// entryAttrs returns the attributes of the record of the statement
+func (d *Database) entryAttrs(entry SqlLogEntry) []slog.Attr {
+	logs := d.logs()
+	logs.mutex.Lock()
+	redactor := logs.argsRedactor
+	logs.mutex.Unlock()
+
+	if redactor == nil {
+		redactor = RedactAll
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", entry.SQL),
+		slog.Any("args", redactor(entry.SQL, entry.Args)),
+		slog.Duration("duration", entry.Duration),
+		slog.Int64("rows_affected", entry.RowsAffected),
+	}
+
+	if entry.TxID != "" {
+		attrs = append(attrs, slog.String("tx_id", entry.TxID))
+	}
+
+	if entry.Err != nil {
+		attrs = append(attrs, slog.String("error", entry.Err.Error()))
+	}
+
+	return attrs
+}
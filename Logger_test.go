@@ -0,0 +1,116 @@
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(buffer *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func testLogRecords(t *testing.T, buffer *bytes.Buffer) []map[string]any {
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerRecords(t *testing.T) {
+	db := newTestDatabase(t)
+
+	buffer := &bytes.Buffer{}
+	db.Logger(newTestLogger(buffer))
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "Tom")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	_, err = db.Exec(`INSERT INTO "missing" ("id") VALUES (1)`)
+	if err == nil {
+		t.Fatal("Expected an error for a missing table")
+	}
+
+	records := testLogRecords(t, buffer)
+	if len(records) != 2 {
+		t.Fatal("Expected 2 records but got: ", records)
+	}
+
+	query := records[0]
+	if query["level"] != "DEBUG" || query["sql"] != `INSERT INTO "users" ("first_name") VALUES (?)` || query["dialect"] != DIALECT_SQLITE || query["rows_affected"] != float64(1) {
+		t.Fatal("Unexpected query record: ", query)
+	}
+
+	// The args are redacted by default
+	if args, isList := query["args"].([]any); !isList || len(args) != 1 || args[0] != "REDACTED" {
+		t.Fatal("Expected the args to be redacted but got: ", query["args"])
+	}
+
+	queryError := records[1]
+	if queryError["level"] != "ERROR" || !strings.Contains(queryError["error"].(string), "no such table") {
+		t.Fatal("Unexpected query error record: ", queryError)
+	}
+}
+
+func TestLoggerLevelsAndRedactor(t *testing.T) {
+	db := newTestDatabase(t)
+
+	buffer := &bytes.Buffer{}
+	db.Logger(newTestLogger(buffer))
+	db.LogLevel(LOG_EVENT_QUERY, slog.LevelInfo)
+	db.LogArgsRedactor(RedactNone)
+
+	err := db.ExecInTransaction(func(tx *Database) error {
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "Tom")
+		return err
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	records := testLogRecords(t, buffer)
+	if len(records) != 1 {
+		t.Fatal("Expected 1 record but got: ", records)
+	}
+
+	if records[0]["level"] != "INFO" || records[0]["tx_id"] == nil {
+		t.Fatal("Unexpected record: ", records[0])
+	}
+
+	if args := records[0]["args"].([]any); args[0] != "Tom" {
+		t.Fatal("Expected the args as they are but got: ", args)
+	}
+}
+
+func TestLoggerTransactionRetry(t *testing.T) {
+	db := newTestDatabase(t)
+
+	buffer := &bytes.Buffer{}
+	db.Logger(newTestLogger(buffer))
+	db.TransactionRetryPolicy(RetryPolicy{MaxAttempts: 2, Backoff: 1})
+
+	err := db.ExecInTransaction(func(tx *Database) error {
+		return errors.New("database is locked")
+	})
+	if err == nil {
+		t.Fatal("Expected the error of the last attempt")
+	}
+
+	records := testLogRecords(t, buffer)
+	if len(records) != 1 || records[0]["level"] != "WARN" || records[0]["msg"] != "sqldb retrying transaction" {
+		t.Fatal("Expected a retry record but got: ", records)
+	}
+}
@@ -3,7 +3,6 @@ package sql
 import (
 	"database/sql"
 	"errors"
-	"time"
 )
 
 func NewDatabaseFromDriver(driverName, dataSourceName string) (*Database, error) {
@@ -18,11 +17,7 @@ func NewDatabaseFromDriver(driverName, dataSourceName string) (*Database, error)
 	}
 
 	return &Database{
-		db:             db,
-		databaseType:   driverName,
-		debug:          false,
-		sqlLogEnabled:  false,
-		sqlLog:         map[string]string{},
-		sqlDurationLog: map[string]time.Duration{},
+		db:           db,
+		databaseType: databaseType,
 	}, nil
 }
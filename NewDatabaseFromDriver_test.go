@@ -21,4 +21,7 @@ func TestNewDatabaseFromDriver(t *testing.T) {
 	if db.tx != nil {
 		t.Fatal("Database tx field MUST BE NIL")
 	}
+	if db.Type() != DIALECT_SQLITE {
+		t.Fatal("Database type MUST BE sqlite but got: ", db.Type())
+	}
 }
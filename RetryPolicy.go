@@ -0,0 +1,100 @@
+package sql
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy re-runs a transaction of ExecInTransaction failing with a
+// transient error, i.e. a deadlock or a serialization failure
+type RetryPolicy struct {
+	// MaxAttempts is the number of runs, the first one included (default 3)
+	MaxAttempts int
+
+	// Backoff is the wait before the second run, doubled for every
+	// following run (default 10ms)
+	Backoff time.Duration
+
+	// MaxBackoff caps the wait between runs (default 1s)
+	MaxBackoff time.Duration
+
+	// IsRetryable decides whether the error is transient, IsRetryableError
+	// when not set
+	IsRetryable func(err error) bool
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 3
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the wait after the failed run attempt, starting from 1
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = 10 * time.Millisecond
+	}
+
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Second
+	}
+
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+func (p RetryPolicy) isRetryable(err error) bool {
+	if p.IsRetryable != nil {
+		return p.IsRetryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// IsRetryableError returns whether the error is a transient transaction
+// failure, worth running the transaction again: a deadlock (MySQL 1213,
+// SQL Server 1205, Postgres 40P01), a Postgres serialization failure (40001),
+// or a busy or locked SQLite database. The drivers are not imported, the
+// errors are recognised by their SQLSTATE and their messages
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// pgx and other drivers expose the SQLSTATE
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		if state == "40001" || state == "40P01" {
+			return true
+		}
+	}
+
+	message := strings.ToLower(err.Error())
+	transientMessages := []string{
+		"deadlock",                   // MySQL, Postgres, SQL Server
+		"could not serialize access", // Postgres 40001
+		"(40001)",                    // MySQL: Error 1213 (40001)
+		"sqlstate 40001",             // pgx and other drivers showing the SQLSTATE
+		"database is locked",         // SQLITE_BUSY
+		"database table is locked",   // SQLITE_LOCKED
+		"sqlite_busy",
+	}
+
+	for _, transientMessage := range transientMessages {
+		if strings.Contains(message, transientMessage) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type testStateError struct {
+	state string
+}
+
+func (e testStateError) Error() string {
+	return "state error"
+}
+
+func (e testStateError) SQLState() string {
+	return e.state
+}
+
+func TestIsRetryableError(t *testing.T) {
+	retryable := []error{
+		errors.New("Error 1213 (40001): Deadlock found when trying to get lock; try restarting transaction"),
+		errors.New("pq: could not serialize access due to concurrent update"),
+		errors.New("database is locked"),
+		errors.New("mssql: Transaction (Process ID 52) was deadlocked on lock resources"),
+		fmt.Errorf("failed to commit transaction: %w", testStateError{state: "40001"}),
+		testStateError{state: "40P01"},
+		errors.New("ERROR: could not serialize access due to read/write dependencies (SQLSTATE 40001)"),
+	}
+
+	for _, err := range retryable {
+		if !IsRetryableError(err) {
+			t.Fatal("Expected retryable: ", err)
+		}
+	}
+
+	notRetryable := []error{
+		nil,
+		errors.New("UNIQUE constraint failed: users.id"),
+		testStateError{state: "23505"},
+		// The SQLSTATE is matched, not any number in the message
+		errors.New("dial tcp 10.0.0.1:40001: connect: connection refused"),
+		errors.New("user 400012 not found"),
+	}
+
+	for _, err := range notRetryable {
+		if IsRetryableError(err) {
+			t.Fatal("Expected not retryable: ", err)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, backoff := range expected {
+		if policy.backoff(i+1) != backoff {
+			t.Fatal("Expected ", backoff, " for attempt ", i+1, " but got: ", policy.backoff(i+1))
+		}
+	}
+
+	if (RetryPolicy{}).maxAttempts() != 3 {
+		t.Fatal("Expected 3 attempts by default")
+	}
+}
@@ -0,0 +1,163 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// SlowQuery is a statement which took at least the slow query threshold
+type SlowQuery struct {
+	SqlLogEntry
+
+	// Plan is the output of EXPLAIN (EXPLAIN QUERY PLAN on SQLite) for the
+	// statement, one line per row, when explaining is enabled
+	Plan string
+
+	// PlanErr is the error of EXPLAIN, i.e. on SQL Server which has none
+	PlanErr error
+}
+
+// SlowQueryThreshold sets the duration from which statements are reported
+// as slow, 0 (the default) turns the detection off
+func (d *Database) SlowQueryThreshold(threshold time.Duration) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.slowThreshold = threshold
+}
+
+// SlowQueryCallback sets the function called with every slow query
+func (d *Database) SlowQueryCallback(callback func(query SlowQuery)) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.slowCallback = callback
+}
+
+// SlowQueryExplain turns attaching the plan to the slow queries on or off.
+// The statement is explained outside of its transaction, after it completed,
+// so the slow query is then recorded and the callback called asynchronously
+func (d *Database) SlowQueryExplain(explain bool) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.slowExplain = explain
+}
+
+// SlowQueries returns the slow queries, oldest first. The last
+// SQL_LOG_CAPACITY ones are kept, whether the SQL log is enabled or not
+func (d *Database) SlowQueries() []SlowQuery {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	return logs.slowQueries.list()
+}
+
+// SlowQueriesEmpty removes all the slow queries
+func (d *Database) SlowQueriesEmpty() {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.slowQueries.keep(nil)
+}
+
+// reportSlowQuery records the slow query and calls the callback, after
+// explaining it when enabled
+func (d *Database) reportSlowQuery(entry SqlLogEntry) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	explain := logs.slowExplain
+	logs.mutex.Unlock()
+
+	report := func(query SlowQuery) {
+		logs.mutex.Lock()
+		logs.slowQueries.add(query)
+		callback := logs.slowCallback
+		logs.mutex.Unlock()
+
+		attrs := d.entryAttrs(query.SqlLogEntry)
+		if query.Plan != "" {
+			attrs = append(attrs, slog.String("plan", query.Plan))
+		}
+		d.logEvent(context.Background(), LOG_EVENT_SLOW_QUERY, "sqldb slow query", attrs...)
+
+		if callback != nil {
+			callback(query)
+		}
+	}
+
+	if !explain {
+		report(SlowQuery{SqlLogEntry: entry})
+		return
+	}
+
+	// The connection of the statement may still be busy with its rows
+	go func() {
+		plan, err := d.explain(entry.SQL, entry.Args)
+		report(SlowQuery{SqlLogEntry: entry, Plan: plan, PlanErr: err})
+	}()
+}
+
+// explain returns the plan of the statement, bypassing the SQL log
+func (d *Database) explain(sqlStr string, args []any) (string, error) {
+	explainSql := ""
+	switch d.databaseType {
+	case DIALECT_SQLITE:
+		explainSql = "EXPLAIN QUERY PLAN " + sqlStr
+	case DIALECT_MYSQL, DIALECT_POSTGRES:
+		explainSql = "EXPLAIN " + sqlStr
+	default:
+		return "", errors.New("explain is not supported for database type " + d.databaseType)
+	}
+
+	rows, err := d.db.QueryContext(context.Background(), explainSql, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	return planToText(rows)
+}
+
+// planToText joins the columns of each row of the plan with spaces, and the
+// rows with new lines
+func planToText(rows *sql.Rows) (string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		valuePointers := make([]any, len(columns))
+		for i := range values {
+			valuePointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePointers...); err != nil {
+			return "", err
+		}
+
+		line := []string{}
+		for _, value := range values {
+			if bytes, isBytes := value.([]byte); isBytes {
+				value = string(bytes)
+			}
+			line = append(line, fmt.Sprint(value))
+		}
+		lines = append(lines, strings.Join(line, " "))
+	}
+
+	return strings.Join(lines, "\n"), rows.Err()
+}
@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowQueryThreshold(t *testing.T) {
+	db := newTestDatabase(t)
+
+	reported := []SlowQuery{}
+	db.SlowQueryThreshold(time.Nanosecond)
+	db.SlowQueryCallback(func(query SlowQuery) {
+		reported = append(reported, query)
+	})
+
+	_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "Tom")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	// The slow queries are recorded with the SQL log disabled
+	if db.SqlLogLen() != 0 {
+		t.Fatal("Expected an empty SQL log but got: ", db.SqlLogLen())
+	}
+
+	slowQueries := db.SlowQueries()
+	if len(slowQueries) != 1 || len(reported) != 1 {
+		t.Fatal("Expected 1 slow query but got: ", slowQueries, reported)
+	}
+
+	if slowQueries[0].SQL != reported[0].SQL || slowQueries[0].Args[0] != "Tom" || slowQueries[0].RowsAffected != 1 || slowQueries[0].Plan != "" {
+		t.Fatal("Unexpected slow query: ", slowQueries[0])
+	}
+
+	db.SlowQueriesEmpty()
+	db.SlowQueryThreshold(time.Hour)
+
+	_, err = db.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "Sam")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	if len(db.SlowQueries()) != 0 {
+		t.Fatal("Expected no slow queries but got: ", db.SlowQueries())
+	}
+}
+
+func TestSlowQueryExplain(t *testing.T) {
+	db := newTestDatabase(t)
+
+	reported := make(chan SlowQuery, 1)
+	db.SlowQueryThreshold(time.Nanosecond)
+	db.SlowQueryExplain(true)
+	db.SlowQueryCallback(func(query SlowQuery) {
+		reported <- query
+	})
+
+	_, err := db.SelectToMapAny(`SELECT "id" FROM "users" WHERE "first_name" = ?`, "Tom")
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	select {
+	case query := <-reported:
+		if query.PlanErr != nil {
+			t.Fatal("Error must be NIL but got: ", query.PlanErr.Error())
+		}
+		if !strings.Contains(query.Plan, "SCAN") {
+			t.Fatal("Expected a SQLite query plan but got: ", query.Plan)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the slow query to be reported")
+	}
+}
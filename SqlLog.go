@@ -0,0 +1,210 @@
+package sql
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SQL_LOG_CAPACITY is the default number of entries kept in the SQL log,
+// and in the slow query log
+const SQL_LOG_CAPACITY = 1000
+
+// SqlLogEntry is a statement executed by the database
+type SqlLogEntry struct {
+	SQL      string
+	Args     []any
+	Start    time.Time
+	Duration time.Duration
+
+	// RowsAffected is the number of rows changed by Exec, -1 for queries
+	// and when the driver does not report it
+	RowsAffected int64
+
+	Err error
+
+	// TxID identifies the transaction the statement ran in, the same for
+	// nested transactions, empty outside of transactions
+	TxID string
+}
+
+// sqlLogState holds the SQL logs, their settings and the hooks, shared by the
+// database and its transaction handles, which may be used by many goroutines
+type sqlLogState struct {
+	mutex   sync.Mutex
+	enabled bool
+	debug   bool
+	entries ring[SqlLogEntry]
+
+	slowThreshold time.Duration
+	slowExplain   bool
+	slowCallback  func(query SlowQuery)
+	slowQueries   ring[SlowQuery]
+
+	logger       *slog.Logger
+	logLevels    map[string]slog.Level
+	argsRedactor ArgsRedactor
+
+	hooks []Hook
+}
+
+func newSqlLogState() *sqlLogState {
+	return &sqlLogState{
+		entries:     ring[SqlLogEntry]{capacity: SQL_LOG_CAPACITY},
+		slowQueries: ring[SlowQuery]{capacity: SQL_LOG_CAPACITY},
+	}
+}
+
+func (l *sqlLogState) isDebug() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.debug
+}
+
+// add appends the entry to the SQL log when enabled
+func (l *sqlLogState) add(entry SqlLogEntry) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.enabled {
+		l.entries.add(entry)
+	}
+}
+
+// ring is a bounded list, once full the oldest items are overwritten
+type ring[T any] struct {
+	capacity int
+	items    []T
+	next     int
+}
+
+func (r *ring[T]) add(item T) {
+	if r.capacity < 1 {
+		return
+	}
+
+	if len(r.items) < r.capacity {
+		r.items = append(r.items, item)
+		r.next = len(r.items) % r.capacity
+		return
+	}
+
+	r.items[r.next] = item
+	r.next = (r.next + 1) % r.capacity
+}
+
+// list returns the items, oldest first
+func (r *ring[T]) list() []T {
+	items := make([]T, 0, len(r.items))
+	if len(r.items) < r.capacity {
+		return append(items, r.items...)
+	}
+
+	items = append(items, r.items[r.next:]...)
+	return append(items, r.items[:r.next]...)
+}
+
+// keep replaces the items with the newest of the given ones
+func (r *ring[T]) keep(items []T) {
+	if len(items) > r.capacity {
+		items = items[len(items)-r.capacity:]
+	}
+
+	r.items = make([]T, len(items), r.capacity)
+	copy(r.items, items)
+	r.next = 0
+	if r.capacity > 0 {
+		r.next = len(items) % r.capacity
+	}
+}
+
+// resize changes the capacity, keeping the newest items
+func (r *ring[T]) resize(capacity int) {
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	items := r.list()
+	r.capacity = capacity
+	r.keep(items)
+}
+
+// shrink leaves the newest items only
+func (r *ring[T]) shrink(leaveLast int) {
+	if len(r.items) <= leaveLast {
+		return
+	}
+
+	if leaveLast < 0 {
+		leaveLast = 0
+	}
+
+	items := r.list()
+	r.keep(items[len(items)-leaveLast:])
+}
+
+// SqlLog returns the logged statements, oldest first
+func (d *Database) SqlLog() []SqlLogEntry {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	return logs.entries.list()
+}
+
+// SqlLogEmpty removes all the logged statements
+func (d *Database) SqlLogEmpty() {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.entries.keep(nil)
+}
+
+// SqlLogLen returns the number of logged statements
+func (d *Database) SqlLogLen() int {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	return len(logs.entries.items)
+}
+
+// SqlLogEnable turns the SQL log on or off
+func (d *Database) SqlLogEnable(enable bool) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.enabled = enable
+}
+
+// SqlLogCapacity sets the number of statements kept in the log, the newest
+// ones are kept when it shrinks (default SQL_LOG_CAPACITY)
+func (d *Database) SqlLogCapacity(capacity int) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.entries.resize(capacity)
+}
+
+// SqlLogShrink removes the logged statements but the last ones
+func (d *Database) SqlLogShrink(leaveLast int) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.entries.shrink(leaveLast)
+}
+
+// DebugEnable turns printing the statements with the standard logger on or
+// off, when there is no Logger
+func (d *Database) DebugEnable(debug bool) {
+	logs := d.logs()
+	logs.mutex.Lock()
+	defer logs.mutex.Unlock()
+
+	logs.debug = debug
+}
@@ -0,0 +1,112 @@
+package sql
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSqlLogRingBuffer(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+	db.SqlLogCapacity(3)
+
+	for i := 1; i <= 5; i++ {
+		_, err := db.Exec(`INSERT INTO "users" ("first_name") VALUES (?)`, "user"+strconv.Itoa(i))
+		if err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+	}
+
+	entries := db.SqlLog()
+	if len(entries) != 3 {
+		t.Fatal("Expected 3 entries but got: ", len(entries))
+	}
+
+	for i, entry := range entries {
+		expected := "user" + strconv.Itoa(i+3)
+		if len(entry.Args) != 1 || entry.Args[0] != expected {
+			t.Fatal("Expected the args ", expected, " but got: ", entry.Args)
+		}
+		if entry.RowsAffected != 1 || entry.Err != nil || entry.TxID != "" || entry.Start.IsZero() {
+			t.Fatal("Unexpected entry: ", entry)
+		}
+	}
+
+	db.SqlLogShrink(1)
+	entries = db.SqlLog()
+	if len(entries) != 1 || entries[0].Args[0] != "user5" {
+		t.Fatal("Expected the last entry to be left but got: ", entries)
+	}
+
+	db.SqlLogEmpty()
+	if db.SqlLogLen() != 0 {
+		t.Fatal("Expected an empty log but got: ", db.SqlLogLen())
+	}
+}
+
+func TestSqlLogCapacityKeepsNewest(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+
+	var value int
+	for i := 1; i <= 4; i++ {
+		if err := db.QueryRow(`SELECT ?`, i).Scan(&value); err != nil {
+			t.Fatal("Error must be NIL but got: ", err.Error())
+		}
+	}
+
+	db.SqlLogCapacity(2)
+	entries := db.SqlLog()
+	if len(entries) != 2 || entries[0].Args[0] != 3 || entries[1].Args[0] != 4 {
+		t.Fatal("Expected the 2 newest entries but got: ", entries)
+	}
+
+	db.SqlLogCapacity(0)
+	if err := db.QueryRow(`SELECT 1`).Scan(&value); err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+	if db.SqlLogLen() != 0 {
+		t.Fatal("Expected nothing to be logged but got: ", db.SqlLogLen())
+	}
+}
+
+func TestSqlLogErrorsAndTransactions(t *testing.T) {
+	db := newTestDatabase(t)
+	db.SqlLogEnable(true)
+
+	_, err := db.Exec(`INSERT INTO "missing" ("id") VALUES (1)`)
+	if err == nil {
+		t.Fatal("Expected an error for a missing table")
+	}
+
+	err = db.ExecInTransaction(func(tx *Database) error {
+		_, err := tx.Exec(`INSERT INTO "users" ("first_name") VALUES ('Tom')`)
+		if err != nil {
+			return err
+		}
+
+		return tx.ExecInTransaction(func(nested *Database) error {
+			_, err := nested.Exec(`INSERT INTO "users" ("first_name") VALUES ('Sam')`)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal("Error must be NIL but got: ", err.Error())
+	}
+
+	entries := db.SqlLog()
+	if entries[0].Err == nil || entries[0].RowsAffected != -1 {
+		t.Fatal("Expected the failed statement to be logged with its error but got: ", entries[0])
+	}
+
+	// The statements of the transaction, savepoints included, share its id
+	txID := entries[1].TxID
+	if txID == "" {
+		t.Fatal("Expected a transaction id")
+	}
+	for _, entry := range entries[1:] {
+		if entry.TxID != txID {
+			t.Fatal("Expected the transaction id ", txID, " but got: ", entry.TxID)
+		}
+	}
+}
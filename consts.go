@@ -4,6 +4,7 @@ package sql
 const DIALECT_MYSQL = "mysql"
 const DIALECT_POSTGRES = "postgres"
 const DIALECT_SQLITE = "sqlite"
+const DIALECT_MSSQL = "mssql"
 
 // Column Attributes
 const COLUMN_ATTRIBUTE_AUTO = "auto"
@@ -31,6 +32,34 @@ const NO = "no"
 const NULL_DATE = "0001-01-01"
 const NULL_DATETIME = "0001-01-01 00:00:00"
 
+// Where operators
+const OPERATOR_BETWEEN = "BETWEEN"
+const OPERATOR_EXISTS = "EXISTS"
+const OPERATOR_ILIKE = "ILIKE"
+const OPERATOR_IN = "IN"
+const OPERATOR_IS_NOT_NULL = "IS NOT NULL"
+const OPERATOR_IS_NULL = "IS NULL"
+const OPERATOR_LIKE = "LIKE"
+const OPERATOR_NOT_BETWEEN = "NOT BETWEEN"
+const OPERATOR_NOT_EXISTS = "NOT EXISTS"
+const OPERATOR_NOT_ILIKE = "NOT ILIKE"
+const OPERATOR_NOT_IN = "NOT IN"
+const OPERATOR_NOT_LIKE = "NOT LIKE"
+
+// Aggregate functions
+const AGGREGATE_AVG = "AVG"
+const AGGREGATE_COUNT = "COUNT"
+const AGGREGATE_COUNT_DISTINCT = "COUNT DISTINCT"
+const AGGREGATE_MAX = "MAX"
+const AGGREGATE_MIN = "MIN"
+const AGGREGATE_SUM = "SUM"
+
+// Join types
+const JOIN_CROSS = "CROSS"
+const JOIN_INNER = "INNER"
+const JOIN_LEFT = "LEFT"
+const JOIN_RIGHT = "RIGHT"
+
 // Sortable
 const ASC = "asc"
 const DESC = "desc"